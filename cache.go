@@ -1,13 +1,44 @@
 package fly
 
+import "context"
+
 type Cache interface {
 	Get(key string) (string, error)
 	Del(key string) error
 	Set(key string, data string) error
 }
 
+// CacheContext the Cache which need the ctx of the model operation
+type CacheContext interface {
+	Cache
+	GetContext(ctx context.Context, key string) (string, error)
+	DelContext(ctx context.Context, key string) error
+	SetContext(ctx context.Context, key string, data string) error
+}
+
 var cache Cache
 
 func SetCache(c Cache) {
 	cache = c
 }
+
+func cacheGet(ctx context.Context, key string) (string, error) {
+	if c, ok := cache.(CacheContext); ok {
+		return c.GetContext(ctx, key)
+	}
+	return cache.Get(key)
+}
+
+func cacheSet(ctx context.Context, key string, data string) error {
+	if c, ok := cache.(CacheContext); ok {
+		return c.SetContext(ctx, key, data)
+	}
+	return cache.Set(key, data)
+}
+
+func cacheDel(ctx context.Context, key string) error {
+	if c, ok := cache.(CacheContext); ok {
+		return c.DelContext(ctx, key)
+	}
+	return cache.Del(key)
+}
@@ -0,0 +1,123 @@
+package fly
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Cursor iterate the rows of a query one by one without loading all of them into memory,
+// the column hooks are applied on each row, but hasOne/hasMany are not loaded.
+//
+//	cur := m.Cursor(fly.WhereGt("id", 0))
+//	defer cur.Close()
+//	for cur.Next() {
+//		row := cur.Row()
+//	}
+//	err := cur.Err()
+type Cursor struct {
+	m       *model
+	ctx     context.Context
+	cancel  context.CancelFunc
+	rows    *sql.Rows
+	scanner *scanner
+	row     Row
+	err     error
+}
+
+func (m *model) Cursor(opt ...Option) *Cursor {
+	var kv []interface{}
+	var err error
+	defer dbLog("Cursor", time.Now(), &err, &kv)
+
+	if m.err != nil {
+		err = m.err
+		return &Cursor{err: m.err}
+	}
+	if _, err = m.tenantId(); err != nil {
+		return &Cursor{err: err}
+	}
+
+	ctx, cancel := m.context("Cursor")
+	cur := &Cursor{m: m, ctx: ctx, cancel: cancel}
+
+	opt = m.scopeOpt(opt)
+	if err = m.checkColumns(ctx, opt); err != nil {
+		cur.err = err
+		cur.Close()
+		return cur
+	}
+
+	opt = m.selectOpt(opt)
+	_sql, args := SelectBuilder(opt...)
+	kv = append(kv, "sql:", _sql, "args:", args)
+
+	cur.rows, err = m.readerOf(opt).QueryContext(ctx, _sql, args...)
+	if err != nil {
+		err = errors.Wrap(err, "fly.Cursor.Query err")
+		cur.err = err
+		cur.Close()
+		return cur
+	}
+
+	cur.scanner, err = newScanner(cur.rows)
+	if err != nil {
+		cur.err = err
+		cur.Close()
+	}
+	return cur
+}
+
+// Next prepare the next row, it returns false when there are no more rows or an error occurred,
+// the cursor is closed automatically at that time
+func (c *Cursor) Next() bool {
+	if c.err != nil || c.rows == nil {
+		return false
+	}
+	if !c.rows.Next() {
+		if err := c.rows.Err(); err != nil {
+			c.err = errors.Wrap(err, "fly.Cursor.rows.Err err")
+		}
+		c.Close()
+		return false
+	}
+	row, err := c.scanner.scan(c.rows)
+	if err != nil {
+		c.err = err
+		c.Close()
+		return false
+	}
+	res, err := c.m.hookOutput(c.ctx, []Row{row})
+	if err != nil {
+		c.err = err
+		c.Close()
+		return false
+	}
+	c.row = res[0]
+	return true
+}
+
+func (c *Cursor) Row() *Row {
+	row := c.row
+	return &row
+}
+
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// Close release the rows, it's safe to call multiple times
+func (c *Cursor) Close() error {
+	var err error
+	if c.rows != nil {
+		err = c.rows.Close()
+		c.rows = nil
+	}
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+	return err
+}
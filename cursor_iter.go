@@ -0,0 +1,23 @@
+//go:build go1.23
+
+package fly
+
+import "iter"
+
+// All iterate the cursor by range-over-func, the cursor is closed when the loop ends or breaks
+//
+//	for row, err := range m.Cursor(fly.WhereGt("id", 0)).All() {
+//	}
+func (c *Cursor) All() iter.Seq2[Row, error] {
+	return func(yield func(Row, error) bool) {
+		defer c.Close()
+		for c.Next() {
+			if !yield(c.row, nil) {
+				return
+			}
+		}
+		if c.err != nil {
+			yield(Row{}, c.err)
+		}
+	}
+}
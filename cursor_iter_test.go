@@ -0,0 +1,17 @@
+//go:build go1.23
+
+package fly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CursorAll(t *testing.T) {
+	for row, err := range m.Cursor(WhereGe("id", 1)).All() {
+		assert.Equal(t, nil, err)
+		assert.NotEqual(t, nil, row.Data)
+		break
+	}
+}
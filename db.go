@@ -18,6 +18,8 @@ type Config struct {
 
 var pool = sync.Map{}
 
+var dialectPool = sync.Map{}
+
 func Init(conns map[string]*Config) error {
 	for conn, conf := range conns {
 		db, err := newDb(conf)
@@ -25,6 +27,7 @@ func Init(conns map[string]*Config) error {
 			return err
 		}
 		pool.Store(conn, db)
+		dialectPool.Store(conn, dialectOf(driverName(conf)))
 		if conf.ReadDsn != "" {
 			rdb, err := newDb(&Config{
 				DSN:         conf.ReadDsn,
@@ -64,13 +67,22 @@ func DB(conn string) (*sql.DB, error) {
 	return db(conn)
 }
 
-func newDb(conf *Config) (*sql.DB, error) {
-	driver := conf.Driver
-	if driver == "" {
-		driver = "mysql"
+func connDialect(conn string) Dialect {
+	if d, ok := dialectPool.Load(conn); ok {
+		return d.(Dialect)
 	}
+	return MySQL
+}
 
-	db, err := sql.Open(driver, conf.DSN)
+func driverName(conf *Config) string {
+	if conf.Driver == "" {
+		return "mysql"
+	}
+	return conf.Driver
+}
+
+func newDb(conf *Config) (*sql.DB, error) {
+	db, err := sql.Open(driverName(conf), conf.DSN)
 	if err != nil {
 		return nil, fmt.Errorf("failed Connection database: %s", err)
 	}
@@ -0,0 +1,220 @@
+package fly
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect render the driver specific parts of sql,
+// builders generate mysql style sql (backtick quoted identifier and ? placeholder) then Rebind it
+type Dialect interface {
+	Name() string
+	// Rebind convert identifier quoting and placeholders of the builder sql
+	Rebind(sql string) string
+	Limit(limit, offset int) (string, []interface{})
+	// Upsert the clause append to insert sql when conflict on the conflict columns
+	Upsert(conflict []string, update []string) string
+	// Returning the clause append to insert sql to get the generated primary key,
+	// empty means the driver support LastInsertId
+	Returning(pk string) string
+	// MaxPlaceholders the max number of placeholders in one statement
+	MaxPlaceholders() int
+	// InsertIds the generated ids of a multi rows insert by its LastInsertId, nil if unknown
+	InsertIds(lastInsertId int64, rows int) []int64
+	// Lock the row locking clause of select, mode is update or share, wait is empty, skip locked or nowait.
+	// empty if the driver not support row lock
+	Lock(mode string, wait string) string
+}
+
+var (
+	MySQL    Dialect = mysqlDialect{}
+	SQLite   Dialect = sqliteDialect{}
+	Postgres Dialect = postgresDialect{}
+)
+
+var drivers = map[string]Dialect{
+	"mysql":    MySQL,
+	"sqlite3":  SQLite,
+	"sqlite":   SQLite,
+	"postgres": Postgres,
+	"pgx":      Postgres,
+}
+
+// RegisterDialect register dialect for the driver name used in Config.Driver
+func RegisterDialect(driver string, d Dialect) {
+	drivers[driver] = d
+}
+
+func dialectOf(driver string) Dialect {
+	if d, ok := drivers[driver]; ok {
+		return d
+	}
+	return MySQL
+}
+
+func dialect(d Dialect) Option {
+	return func(opts *Options) {
+		opts.dialect = d
+	}
+}
+
+func getDialect(opts *Options) Dialect {
+	if opts.dialect == nil {
+		return MySQL
+	}
+	return opts.dialect
+}
+
+func limit(limit, offset int) (string, []interface{}) {
+	return " limit ? offset ? ", []interface{}{limit, offset}
+}
+
+// consecutiveIds ids from first, innodb and sqlite allocate the ids of one insert statement consecutively
+func consecutiveIds(first int64, rows int) []int64 {
+	ids := make([]int64, 0, rows)
+	for i := 0; i < rows; i++ {
+		ids = append(ids, first+int64(i))
+	}
+	return ids
+}
+
+func upsertSet(update []string, format string) string {
+	var set []string
+	for _, v := range update {
+		set = append(set, strings.ReplaceAll(format, "%s", quote(v)))
+	}
+	return strings.Join(set, ", ")
+}
+
+func onConflict(conflict []string, update []string) string {
+	var keys []string
+	for _, v := range conflict {
+		keys = append(keys, quote(v))
+	}
+	if len(update) == 0 {
+		return " on conflict (" + strings.Join(keys, ", ") + ") do nothing"
+	}
+	return " on conflict (" + strings.Join(keys, ", ") + ") do update set " + upsertSet(update, "%s = excluded.%s")
+}
+
+func forLock(mode string, wait string) string {
+	if wait != "" {
+		return " for " + mode + " " + wait
+	}
+	return " for " + mode
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) Rebind(sql string) string { return sql }
+
+func (mysqlDialect) Limit(l, offset int) (string, []interface{}) { return limit(l, offset) }
+
+func (mysqlDialect) Upsert(conflict []string, update []string) string {
+	if len(update) == 0 {
+		// keep the row as it is
+		return " on duplicate key update " + upsertSet(conflict, "%s = %s")
+	}
+	return " on duplicate key update " + upsertSet(update, "%s = values(%s)")
+}
+
+func (mysqlDialect) Returning(pk string) string { return "" }
+
+func (mysqlDialect) MaxPlaceholders() int { return 65535 }
+
+// InsertIds LastInsertId of mysql is the id of the first row, the ids are consecutive from it
+// only if auto_increment_increment is 1 (it's not by default in galera or group replication cluster)
+func (mysqlDialect) InsertIds(lastInsertId int64, rows int) []int64 {
+	return consecutiveIds(lastInsertId, rows)
+}
+
+func (mysqlDialect) Lock(mode string, wait string) string { return forLock(mode, wait) }
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite3" }
+
+func (sqliteDialect) Rebind(sql string) string { return sql }
+
+func (sqliteDialect) Limit(l, offset int) (string, []interface{}) { return limit(l, offset) }
+
+func (sqliteDialect) Upsert(conflict []string, update []string) string {
+	return onConflict(conflict, update)
+}
+
+func (sqliteDialect) Returning(pk string) string { return "" }
+
+// MaxPlaceholders SQLITE_MAX_VARIABLE_NUMBER of sqlite before 3.32.0
+func (sqliteDialect) MaxPlaceholders() int { return 999 }
+
+// InsertIds LastInsertId of sqlite is the rowid of the last row
+func (sqliteDialect) InsertIds(lastInsertId int64, rows int) []int64 {
+	return consecutiveIds(lastInsertId-int64(rows)+1, rows)
+}
+
+// Lock sqlite lock the whole database by transaction, no row lock
+func (sqliteDialect) Lock(mode string, wait string) string { return "" }
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+// Rebind `name` => "name", ? => $n, string literal is kept as it is.
+// in the identifier the doubled backtick is unescaped and the double quote is doubled
+func (postgresDialect) Rebind(sql string) string {
+	var b strings.Builder
+	b.Grow(len(sql) + 8)
+	n := 0
+	inString, inIdent := false, false
+	runes := []rune(sql)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case inString:
+			inString = c != '\''
+			b.WriteRune(c)
+		case inIdent:
+			switch {
+			case c == '`' && i+1 < len(runes) && runes[i+1] == '`':
+				b.WriteRune('`')
+				i++
+			case c == '`':
+				inIdent = false
+				b.WriteRune('"')
+			case c == '"':
+				b.WriteString(`""`)
+			default:
+				b.WriteRune(c)
+			}
+		case c == '\'':
+			inString = true
+			b.WriteRune(c)
+		case c == '`':
+			inIdent = true
+			b.WriteRune('"')
+		case c == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func (postgresDialect) Limit(l, offset int) (string, []interface{}) { return limit(l, offset) }
+
+func (postgresDialect) Upsert(conflict []string, update []string) string {
+	return onConflict(conflict, update)
+}
+
+func (postgresDialect) Returning(pk string) string { return " returning " + quote(pk) }
+
+func (postgresDialect) MaxPlaceholders() int { return 65535 }
+
+// InsertIds the ids are returned by the returning clause
+func (postgresDialect) InsertIds(lastInsertId int64, rows int) []int64 { return nil }
+
+func (postgresDialect) Lock(mode string, wait string) string { return forLock(mode, wait) }
@@ -0,0 +1,59 @@
+package fly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresRebind(t *testing.T) {
+	sql := Postgres.Rebind("select `id` from `user` where `name` = ? and `memo` != '`?`' and `age` > ?")
+	assert.Equal(t, `select "id" from "user" where "name" = $1 and "memo" != '`+"`?`"+`' and "age" > $2`, sql)
+}
+
+func TestDialectBuilder(t *testing.T) {
+	sql, args := SelectBuilder(
+		dialect(Postgres),
+		table("user"),
+		WhereEq("id", 1),
+		WhereInSub("level_id", Table("level"), Field("id"), WhereGt("score", 10), Limit(5)),
+		Limit(10),
+	)
+	assert.Equal(t, `select * from "user" where "id" = $1 and "level_id" in (select "id" from "level" where "score" > $2 limit $3 offset $4 ) limit $5 offset $6 `, sql)
+	assert.Equal(t, []interface{}{1, 10, 5, 0, 10, 0}, args)
+
+	sql, _ = InsertBuilder(dialect(Postgres), table("user"), Field("name"), Value("fly"), returning("id"))
+	assert.Equal(t, `insert into "user" ("name") values ($1) returning "id"`, sql)
+
+	sql, _ = InsertBuilder(dialect(MySQL), table("user"), Field("name"), Value("fly"), returning("id"))
+	assert.Equal(t, "insert into `user` (`name`) values (?)", sql)
+}
+
+func TestInsertBuilderUpsert(t *testing.T) {
+	sql, args := InsertBuilder(dialect(Postgres), table("user"), Field("name", "age"), Values([]interface{}{"a", 1}, []interface{}{"b", 2}), conflictUpdate([]string{"name"}, []string{"age"}))
+	assert.Equal(t, `insert into "user" ("name", "age") values ($1,$2),($3,$4) on conflict ("name") do update set "age" = excluded."age"`, sql)
+	assert.Equal(t, []interface{}{"a", 1, "b", 2}, args)
+
+	sql, _ = InsertBuilder(dialect(MySQL), table("user"), Field("name", "age"), Value("a", 1), conflictUpdate([]string{"name"}, []string{"age"}))
+	assert.Equal(t, "insert into `user` (`name`, `age`) values (?,?) on duplicate key update `age` = values(`age`)", sql)
+}
+
+func TestDialectUpsert(t *testing.T) {
+	assert.Equal(t, " on duplicate key update `name` = values(`name`), `age` = values(`age`)", MySQL.Upsert([]string{"id"}, []string{"name", "age"}))
+	assert.Equal(t, " on conflict (`id`) do update set `name` = excluded.`name`", SQLite.Upsert([]string{"id"}, []string{"name"}))
+	assert.Equal(t, " on conflict (`a`, `b`) do nothing", Postgres.Upsert([]string{"a", "b"}, nil))
+}
+
+func TestSelectBuilderLock(t *testing.T) {
+	sql, _ := SelectBuilder(table("user"), WhereEq("id", 1), Limit(1), lock(lockUpdate))
+	assert.Equal(t, "select * from `user` where `id` = ? limit ? offset ?  for update", sql)
+
+	sql, _ = SelectBuilder(dialect(SQLite), table("user"), WhereEq("id", 1), lock(lockUpdate))
+	assert.Equal(t, "select * from `user` where `id` = ?", sql)
+
+	sql, _ = SelectBuilder(dialect(Postgres), table("job"), LockForShare(), NoWait())
+	assert.Equal(t, `select * from "job" for share nowait`, sql)
+
+	sql, _ = SelectBuilder(table("job"), WhereEq("status", 0), Limit(10), SkipLocked())
+	assert.Equal(t, "select * from `job` where `status` = ? limit ? offset ?  for update skip locked", sql)
+}
@@ -1,6 +1,7 @@
 package fly
 
 import (
+	"context"
 	"database/sql"
 	"strings"
 	"time"
@@ -8,9 +9,20 @@ import (
 	"github.com/pkg/errors"
 )
 
+// executor is *sql.DB or *sql.Tx
+type executor interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
 // 一般用Prepared Statements和Exec()完成INSERT, UPDATE, DELETE操作
-func exec(db *sql.DB, _sql string, args ...interface{}) (res sql.Result, err error) {
-	tx, err := db.Begin()
+// 在事务中执行时直接使用该事务, 否则为每次操作开启一个事务
+func exec(ctx context.Context, conn executor, _sql string, args ...interface{}) (res sql.Result, err error) {
+	if tx, ok := conn.(*sql.Tx); ok {
+		return execStmt(ctx, tx, _sql, args...)
+	}
+	tx, err := conn.(*sql.DB).BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -19,12 +31,41 @@ func exec(db *sql.DB, _sql string, args ...interface{}) (res sql.Result, err err
 			_ = tx.Rollback()
 		}
 	}()
-	stmt, err := tx.Prepare(_sql)
+	res, err = execStmt(ctx, tx, _sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func execStmt(ctx context.Context, tx *sql.Tx, _sql string, args ...interface{}) (sql.Result, error) {
+	stmt, err := tx.PrepareContext(ctx, _sql)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
-	res, err = stmt.Exec(args...)
+	return stmt.ExecContext(ctx, args...)
+}
+
+// execReturning exec insert sql with returning clause, and scan the returned primary keys
+func execReturning(ctx context.Context, conn executor, _sql string, args ...interface{}) (ids []int64, err error) {
+	if tx, ok := conn.(*sql.Tx); ok {
+		return queryIds(ctx, tx, _sql, args...)
+	}
+	tx, err := conn.(*sql.DB).BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	ids, err = queryIds(ctx, tx, _sql, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -32,17 +73,44 @@ func exec(db *sql.DB, _sql string, args ...interface{}) (res sql.Result, err err
 	if err != nil {
 		return nil, err
 	}
-	return res, nil
+	return ids, nil
 }
 
-func query(db *sql.DB, _sql string, args ...interface{}) (result []Row, err error) {
-	stmt, err := db.Prepare(_sql)
+func queryIds(ctx context.Context, tx *sql.Tx, _sql string, args ...interface{}) (ids []int64, err error) {
+	stmt, err := tx.PrepareContext(ctx, _sql)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return ids, nil
+}
+
+func query(ctx context.Context, db executor, _sql string, args ...interface{}) (result []Row, err error) {
+	stmt, err := db.PrepareContext(ctx, _sql)
 	if err != nil {
 		return nil, errors.Wrap(err, "fly.exec.Prepare err")
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(args...)
+	rows, err := stmt.QueryContext(ctx, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, "fly.exec.Query err")
 	}
@@ -55,7 +123,7 @@ func destination(columnTypes []*sql.ColumnType) func() []interface{} {
 	dest := make([]func() interface{}, 0, len(columnTypes))
 	for _, v := range columnTypes {
 		switch strings.ToUpper(v.DatabaseTypeName()) {
-		case "VARCHAR", "CHAR", "TEXT", "NVARCHAR", "LONGTEXT", "LONGBLOB", "MEDIUMTEXT", "MEDIUMBLOB", "BLOB", "TINYTEXT", "DECIMAL":
+		case "VARCHAR", "CHAR", "TEXT", "NVARCHAR", "LONGTEXT", "LONGBLOB", "MEDIUMTEXT", "MEDIUMBLOB", "BLOB", "TINYTEXT", "DECIMAL", "NUMERIC", "BPCHAR", "JSON", "JSONB", "UUID":
 			if nullable, _ := v.Nullable(); nullable {
 				dest = append(dest, func() interface{} {
 					return new(sql.NullString)
@@ -65,21 +133,32 @@ func destination(columnTypes []*sql.ColumnType) func() []interface{} {
 					return new(string)
 				})
 			}
-		case "INT", "TINYINT", "INTEGER", "SMALLINT", "MEDIUMINT", "TINYINTEGER":
+		case "INT", "TINYINT", "INTEGER", "SMALLINT", "MEDIUMINT", "TINYINTEGER", "INT2", "INT4":
 			dest = append(dest, func() interface{} {
 				return new(int)
 			})
-		case "BIGINT":
+		case "BIGINT", "INT8":
 			dest = append(dest, func() interface{} {
 				return new(int64)
 			})
-		case "DATETIME", "DATE", "TIMESTAMP", "TIME":
+		case "DATETIME", "DATE", "TIMESTAMP", "TIME", "TIMESTAMPTZ", "TIMETZ":
+			// e.g. deleted_at of WithDeletedAt, the driver not report nullable (sqlite) is treated as nullable
+			if nullable, ok := v.Nullable(); nullable || !ok {
+				dest = append(dest, func() interface{} {
+					return new(sql.NullTime)
+				})
+			} else {
+				dest = append(dest, func() interface{} {
+					return new(time.Time)
+				})
+			}
+		case "DOUBLE", "FLOAT", "FLOAT4", "FLOAT8":
 			dest = append(dest, func() interface{} {
-				return new(time.Time)
+				return new(float64)
 			})
-		case "DOUBLE", "FLOAT":
+		case "BOOL":
 			dest = append(dest, func() interface{} {
-				return new(float64)
+				return new(bool)
 			})
 		default:
 			dest = append(dest, func() interface{} {
@@ -96,36 +175,62 @@ func destination(columnTypes []*sql.ColumnType) func() []interface{} {
 	}
 }
 
-func rows2SliceMap(rows *sql.Rows) (list []Row, err error) {
+// scanner scan *sql.Rows into Row one by one
+type scanner struct {
+	columns []string
+	dest    func() []interface{}
+}
+
+func newScanner(rows *sql.Rows) (*scanner, error) {
 	columns, err := rows.Columns()
 	if err != nil {
 		return nil, errors.Wrap(err, "fly.rows2SliceMap.columns err")
 	}
-	length := len(columns)
 
 	columnTypes, err := rows.ColumnTypes()
 	if err != nil {
 		return nil, errors.Wrap(err, "fly.rows2SliceMap.ColumnTypes err")
 	}
 
-	dest := destination(columnTypes)
+	return &scanner{columns: columns, dest: destination(columnTypes)}, nil
+}
 
-	for rows.Next() {
-		tmp := dest()
-		err = rows.Scan(tmp...)
-		if err != nil {
-			return nil, errors.Wrap(err, "fly.rows2SliceMap.Scan err")
-		}
-		row := new(Row)
-		row.Data = map[string]interface{}{}
-		for i := 0; i < length; i++ {
-			if val, ok := tmp[i].(*sql.NullString); ok {
-				row.Data[columns[i]] = val.String
+func (s *scanner) scan(rows *sql.Rows) (Row, error) {
+	tmp := s.dest()
+	err := rows.Scan(tmp...)
+	if err != nil {
+		return Row{}, errors.Wrap(err, "fly.rows2SliceMap.Scan err")
+	}
+	row := Row{Data: map[string]interface{}{}}
+	for i, column := range s.columns {
+		switch val := tmp[i].(type) {
+		case *sql.NullString:
+			row.Data[column] = val.String
+		case *sql.NullTime:
+			if val.Valid {
+				row.Data[column] = &val.Time
 			} else {
-				row.Data[columns[i]] = tmp[i]
+				row.Data[column] = nil
 			}
+		default:
+			row.Data[column] = tmp[i]
+		}
+	}
+	return row, nil
+}
+
+func rows2SliceMap(rows *sql.Rows) (list []Row, err error) {
+	s, err := newScanner(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		row, err := s.scan(rows)
+		if err != nil {
+			return nil, err
 		}
-		list = append(list, *row)
+		list = append(list, row)
 	}
 	if err = rows.Err(); err != nil {
 		return nil, errors.Wrap(err, "fly.rows2SliceMap.rows.Err err")
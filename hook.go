@@ -1,6 +1,8 @@
 package fly
 
 import (
+	"context"
+
 	"github.com/daodao97/fly/interval/hook"
 )
 
@@ -9,6 +11,27 @@ type HookData interface {
 	Output(row map[string]interface{}, fieldValue interface{}) (interface{}, error)
 }
 
+// HookDataContext the HookData which need the ctx of the model operation
+type HookDataContext interface {
+	HookData
+	InputContext(ctx context.Context, row map[string]interface{}, fieldValue interface{}) (interface{}, error)
+	OutputContext(ctx context.Context, row map[string]interface{}, fieldValue interface{}) (interface{}, error)
+}
+
+func hookInput(ctx context.Context, h HookData, row map[string]interface{}, fieldValue interface{}) (interface{}, error) {
+	if hc, ok := h.(HookDataContext); ok {
+		return hc.InputContext(ctx, row, fieldValue)
+	}
+	return h.Input(row, fieldValue)
+}
+
+func hookOutput(ctx context.Context, h HookData, row map[string]interface{}, fieldValue interface{}) (interface{}, error) {
+	if hc, ok := h.(HookDataContext); ok {
+		return hc.OutputContext(ctx, row, fieldValue)
+	}
+	return h.Output(row, fieldValue)
+}
+
 type Hook = func() (string, HookData)
 
 func Json(field string) Hook {
@@ -1,10 +1,14 @@
 package fly
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/spf13/cast"
 )
 
 var ErrNotFound = errors.New("record not found")
@@ -15,14 +19,53 @@ type Model interface {
 	PrimaryKey() string
 	Select(opt ...Option) (rows *Rows)
 	SelectOne(opt ...Option) *Row
+	Cursor(opt ...Option) *Cursor
+	Chunk(size int, fn func(rows *Rows) error, opt ...Option) error
+	ChunkById(size int, fn func(rows *Rows) error, opt ...Option) error
+	Paginate(page, size int, opt ...Option) (*Page, error)
+	PaginateCursor(cursor string, size int, opt ...Option) (*CursorPage, error)
 	Count(opt ...Option) (count int64, err error)
+	Sum(field string, opt ...Option) (float64, error)
+	Avg(field string, opt ...Option) (float64, error)
+	Min(field string, opt ...Option) (float64, error)
+	Max(field string, opt ...Option) (float64, error)
 	Insert(record Record) (lastId int64, err error)
+	InsertMany(records []Record, batchSize int) (count int64, ids []int64, err error)
+	Upsert(record Record, conflict []string, update []string) (inserted bool, err error)
+	UpsertMany(records []Record, conflict []string, update []string, batchSize int) (inserted []bool, err error)
+	FirstOrCreate(where Record, defaults Record) (row *Row, created bool, err error)
+	UpdateOrCreate(where Record, values Record) (row *Row, created bool, err error)
 	Update(record Record, opt ...Option) (ok bool, err error)
+	UpdateWithResult(record Record, opt ...Option) (res *UpdateResult, err error)
+	Increment(field string, n interface{}, opt ...Option) (ok bool, err error)
+	Decrement(field string, n interface{}, opt ...Option) (ok bool, err error)
 	Delete(opt ...Option) (ok bool, err error)
+	DeleteWithResult(opt ...Option) (res *DeleteResult, err error)
+	Restore(opt ...Option) (ok bool, err error)
+	ForceDelete(opt ...Option) (ok bool, err error)
 	Exec(query string, args ...interface{}) (sql.Result, error)
 	Query(query string, args ...interface{}) (*sql.Rows, error)
 	FindBy(id int64) *Row
 	UpdateBy(id int64, record Record) (bool, error)
+	WithTx(tx *Tx) Model
+	Ctx(ctx context.Context) Model
+	WithoutTimestamps() Model
+	Scope(name string) Model
+	Tenant(id interface{}) Model
+}
+
+type UpdateResult struct {
+	RowsAffected int64
+	SQL          string
+	Args         []interface{}
+	// Version the new version of WithVersionColumn, 0 if the version is not checked
+	Version int64
+}
+
+type DeleteResult struct {
+	RowsAffected int64
+	SQL          string
+	Args         []interface{}
 }
 
 type model struct {
@@ -30,6 +73,7 @@ type model struct {
 	database        string
 	table           string
 	fakeDelKey      string
+	deletedAt       bool
 	primaryKey      string
 	columnHook      map[string]HookData
 	columnValidator []Valid
@@ -38,8 +82,25 @@ type model struct {
 	options         *Options
 	client          *sql.DB
 	readClient      *sql.DB
+	dialect         Dialect
+	tx              *Tx
+	ctx             context.Context
+	timeout         map[string]time.Duration
 	saveZero        bool
 	enableValidator bool
+	strict          bool
+	columns         *columnSet
+	createdAt       string
+	updatedAt       string
+	unixTime        bool
+	skipTimestamps  bool
+	clock           func() time.Time
+	location        *time.Location
+	version         string
+	globalScopes    []scope
+	scopes          map[string][]Option
+	scoped          [][]Option
+	tenantKey       string
 	err             error
 }
 
@@ -68,6 +129,9 @@ func New(table string, baseOpt ...With) *model {
 			m.readClient = readClient
 		}
 	}
+	if m.dialect == nil {
+		m.dialect = connDialect(m.connection)
+	}
 	m.enableValidator = true
 	return m
 }
@@ -76,6 +140,87 @@ func (m *model) PrimaryKey() string {
 	return m.primaryKey
 }
 
+// WithTx return a copy of the model which all operations run in the transaction
+func (m *model) WithTx(tx *Tx) Model {
+	return m.withTx(tx)
+}
+
+func (m *model) withTx(tx *Tx) *model {
+	_m := *m
+	_m.tx = tx
+	return &_m
+}
+
+// Ctx return a copy of the model which all operations run with the ctx
+func (m *model) Ctx(ctx context.Context) Model {
+	return m.withCtx(ctx)
+}
+
+func (m *model) withCtx(ctx context.Context) *model {
+	_m := *m
+	_m.ctx = ctx
+	return &_m
+}
+
+// context return the ctx of operation op with the timeout defined by WithTimeout
+func (m *model) context(op string) (context.Context, context.CancelFunc) {
+	ctx := m.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	d, ok := m.timeout[op]
+	if !ok {
+		d, ok = m.timeout[""]
+	}
+	if ok && d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return ctx, func() {}
+}
+
+// txOf the transaction bound by WithTx, or the one carried by the ctx of model on the same connection,
+// so the model of tx.Context() runs in the transaction as if WithTx
+func (m *model) txOf() *Tx {
+	if m.tx != nil {
+		return m.tx
+	}
+	if m.ctx != nil {
+		if tx, ok := TxFromContext(m.ctx); ok && tx.conn == m.connection {
+			return tx
+		}
+	}
+	return nil
+}
+
+func (m *model) writer() executor {
+	if tx := m.txOf(); tx != nil {
+		return tx.tx
+	}
+	return m.client
+}
+
+func (m *model) reader() executor {
+	if tx := m.txOf(); tx != nil {
+		return tx.tx
+	}
+	if m.readClient != nil {
+		return m.readClient
+	}
+	return m.client
+}
+
+// readerOf the locking read must be done by the write client
+func (m *model) readerOf(opt []Option) executor {
+	opts := new(Options)
+	for _, o := range opt {
+		o(opts)
+	}
+	if opts.lock != "" {
+		return m.writer()
+	}
+	return m.reader()
+}
+
 func (m *model) Select(opt ...Option) (rows *Rows) {
 	var kv []interface{}
 	var err error
@@ -85,58 +230,69 @@ func (m *model) Select(opt ...Option) (rows *Rows) {
 		err = m.err
 		return &Rows{Err: m.err}
 	}
-	opts := new(Options)
-	opt = append(opt, table(m.table), database(m.database))
-	if m.fakeDelKey != "" {
-		opt = append(opt, WhereEq(m.fakeDelKey, 0))
+	if _, err = m.tenantId(); err != nil {
+		return &Rows{Err: err}
 	}
-	for _, o := range opt {
-		o(opts)
+
+	ctx, cancel := m.context("Select")
+	defer cancel()
+
+	opt = m.scopeOpt(opt)
+	if err = m.checkColumns(ctx, opt); err != nil {
+		return &Rows{Err: err}
 	}
 
+	opt = m.selectOpt(m.versionField(opt))
 	_sql, args := SelectBuilder(opt...)
-	client := m.client
-	if m.readClient != nil {
-		client = m.readClient
-	}
-	res, err := query(client, _sql, args...)
+	res, err := query(ctx, m.readerOf(opt), _sql, args...)
 	kv = append(kv, "sql:", _sql, "args:", args)
 	if err != nil {
 		return &Rows{Err: err}
 	}
 
 	for _, has := range m.hasOne {
-		res, err = m.hasOneData(res, has)
+		res, err = m.hasOneData(ctx, res, has)
 		if err != nil {
 			return &Rows{Err: err}
 		}
 	}
 
 	for _, has := range m.hasMany {
-		res, err = m.hasManyData(res, has)
+		res, err = m.hasManyData(ctx, res, has)
 		if err != nil {
 			return &Rows{Err: err}
 		}
 	}
 
-	for k, v := range m.columnHook {
-		for i, r := range res {
-			for field, val := range r.Data {
-				if k == field {
-					overVal, err1 := v.Output(res[i].Data, val)
-					if err1 != nil {
-						err = err1
-						return &Rows{Err: err}
-					}
-					res[i].Data[field] = overVal
-				}
-			}
-		}
+	res, err = m.hookOutput(ctx, res)
+	if err != nil {
+		return &Rows{Err: err}
 	}
 
 	return &Rows{List: res, Err: err}
 }
 
+// selectOpt append the table and fake delete condition of the model to opt,
+// a derived table (FromSub) is left to its own options
+func (m *model) selectOpt(opt []Option) []Option {
+	opts := new(Options)
+	opt = append(opt, table(m.table), database(m.database), dialect(m.dialect))
+	for _, o := range opt {
+		o(opts)
+	}
+	if m.fakeDelKey != "" && opts.from == nil {
+		key := m.qualify(opts, m.fakeDelKey)
+		switch opts.trashed {
+		case trashedWith:
+		case trashedOnly:
+			opt = append(groupOr(opt), m.trashedCond(key))
+		default:
+			opt = append(groupOr(opt), m.aliveCond(key))
+		}
+	}
+	return opt
+}
+
 func (m *model) SelectOne(opt ...Option) *Row {
 	opt = append(opt, Limit(1))
 	rows := m.Select(opt...)
@@ -152,11 +308,35 @@ func (m *model) SelectOne(opt ...Option) *Row {
 }
 
 func (m *model) Count(opt ...Option) (count int64, err error) {
-	opt = append(opt, table(m.table), AggregateCount("*"))
+	// the options shared with Select, e.g. the filters of Paginate, order and limit is useless for count
+	opt = append(m.scopeOpt(opt), func(o *Options) {
+		o.orderBy = nil
+		o.limit = 0
+		o.offset = 0
+	})
+	opts := new(Options)
+	for _, o := range opt {
+		o(opts)
+	}
+	ctx, cancel := m.context("Count")
+	defer cancel()
+	if err = m.checkColumns(ctx, opt); err != nil {
+		return 0, err
+	}
+	if len(opts.groupBy) > 0 || len(opts.union) > 0 {
+		// count the groups (or the rows of union) instead of the rows of first group
+		opt = append(opt, func(o *Options) {
+			if len(o.field) == 0 && len(o.fieldSub) == 0 {
+				o.field = o.groupBy
+			}
+		})
+		opt = []Option{FromSub("t", m.selectOpt(opt)...)}
+	}
+	opt = append(opt, table(m.table), aggregate("count(*) as count"))
 	var result struct {
 		Count int64
 	}
-	err = m.SelectOne(opt...).Binding(&result)
+	err = m.withCtx(ctx).SelectOne(opt...).Binding(&result)
 	if err != nil {
 		return 0, err
 	}
@@ -164,6 +344,36 @@ func (m *model) Count(opt ...Option) (count int64, err error) {
 	return result.Count, nil
 }
 
+func (m *model) Sum(field string, opt ...Option) (float64, error) {
+	return m.aggregate("sum", field, opt...)
+}
+
+func (m *model) Avg(field string, opt ...Option) (float64, error) {
+	return m.aggregate("avg", field, opt...)
+}
+
+func (m *model) Min(field string, opt ...Option) (float64, error) {
+	return m.aggregate("min", field, opt...)
+}
+
+func (m *model) Max(field string, opt ...Option) (float64, error) {
+	return m.aggregate("max", field, opt...)
+}
+
+func (m *model) aggregate(fn, field string, opt ...Option) (float64, error) {
+	ctx, cancel := m.context(strings.ToUpper(fn[:1]) + fn[1:])
+	defer cancel()
+	if err := m.checkColumns(ctx, nil, field); err != nil {
+		return 0, err
+	}
+	opt = append(m.scopeOpt(opt), aggregate(fmt.Sprintf("coalesce(%s, 0) as aggregate", aggregateField(fn, field))))
+	row := m.withCtx(ctx).SelectOne(opt...)
+	if row.Err != nil {
+		return 0, row.Err
+	}
+	return cast.ToFloat64E(row.Data["aggregate"])
+}
+
 func (m *model) Insert(record Record) (lastId int64, err error) {
 	if m.err != nil {
 		return 0, m.err
@@ -172,154 +382,324 @@ func (m *model) Insert(record Record) (lastId int64, err error) {
 	var kv []interface{}
 	defer dbLog("Insert", time.Now(), &err, &kv)
 
+	ctx, cancel := m.context("Insert")
+	defer cancel()
+
+	_record, err := m.insertRecord(ctx, record)
+	if err != nil {
+		return 0, err
+	}
+
+	ks, vs := m.recordToKV(_record)
+	_sql, args := InsertBuilder(dialect(m.dialect), table(m.table), Field(ks...), Value(vs...), returning(m.primaryKey))
+	kv = append(kv, "sql:", _sql, "args:", vs)
+
+	if m.dialect.Returning(m.primaryKey) != "" {
+		ids, err := execReturning(ctx, m.writer(), _sql, args...)
+		if err != nil {
+			return 0, err
+		}
+		return ids[0], nil
+	}
+
+	result, err := exec(ctx, m.writer(), _sql, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// insertRecord run the column hooks and validators on the record to insert
+func (m *model) insertRecord(ctx context.Context, record Record) (Record, error) {
 	_record := record
 	//_record, err := util.DecodeToMap(record, m.saveZero)
 	//if err != nil {
 	//	return 0, err
 	//}
 	if len(_record) == 0 {
-		return 0, errors.New("empty record to insert, if your record is struct please set db tag")
+		return nil, errors.New("empty record to insert, if your record is struct please set db tag")
 	}
 
-	_record, err = m.hookInput(_record)
+	if err := m.checkColumns(ctx, nil, sortedKeys(_record)...); err != nil {
+		return nil, err
+	}
+
+	_record, err := m.tenantRecord(_record, true)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	_record = m.touch(_record, m.createdAt, m.updatedAt)
+	_record, err = m.hookInput(ctx, _record)
+	if err != nil {
+		return nil, err
 	}
 
-	if m.enableValidator {
-		for _, v := range m.columnValidator {
-			err = v(NewValidOpt(withRow(_record), WithModel(m)))
-			if err != nil {
-				return 0, err
-			}
-		}
+	if err = m.valid(ctx, _record); err != nil {
+		return nil, err
 	}
 
 	delete(_record, m.primaryKey)
 	if len(_record) == 0 {
-		return 0, errors.New("empty record to insert")
+		return nil, errors.New("empty record to insert")
 	}
+	return _record, nil
+}
 
-	ks, vs := m.recordToKV(_record)
-	_sql, args := InsertBuilder(table(m.table), Field(ks...), Value(vs...))
-	kv = append(kv, "sql:", _sql, "args:", vs)
-
-	result, err := exec(m.client, _sql, args...)
-	if err != nil {
-		return 0, err
+// valid run the column validators on the record
+func (m *model) valid(ctx context.Context, record Record) error {
+	if !m.enableValidator {
+		return nil
 	}
-
-	return result.LastInsertId()
+	for _, v := range m.columnValidator {
+		if err := v(NewValidOpt(withRow(record), WithModel(m.withCtx(ctx)), withContext(ctx))); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (m *model) Update(record Record, opt ...Option) (ok bool, err error) {
+	if _, err = m.UpdateWithResult(record, opt...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UpdateWithResult update and report the affected rows, in strict mode (WithStrict) it returns ErrNotFound
+// when the record has primary key and the row not exists
+func (m *model) UpdateWithResult(record Record, opt ...Option) (res *UpdateResult, err error) {
 	if m.err != nil {
-		return false, m.err
+		return nil, m.err
 	}
 
 	var kv []interface{}
 	defer dbLog("Update", time.Now(), &err, &kv)
 
+	ctx, cancel := m.context("Update")
+	defer cancel()
+
 	_record := record
 	//_record, err := util.DecodeToMap(record, m.saveZero)
 	//if err != nil {
 	//	return false, err
 	//}
 	if len(_record) == 0 {
-		return false, errors.New("empty record to update, if your record is struct please set db tag")
+		return nil, errors.New("empty record to update, if your record is struct please set db tag")
+	}
+
+	if _record, err = m.tenantRecord(_record, false); err != nil {
+		return nil, err
 	}
 
-	if id, ok := _record[m.primaryKey]; ok {
+	opt = m.scopeOpt(opt)
+	if err = m.checkColumns(ctx, opt, sortedKeys(_record)...); err != nil {
+		return nil, err
+	}
+
+	_record = m.touch(_record, m.updatedAt)
+	id, byPk := _record[m.primaryKey]
+	if byPk {
 		kv = append(kv, "id:", id)
-		opt = append(opt, WhereEq(m.primaryKey, id))
+		opt = append(groupOr(opt), WhereEq(m.primaryKey, id))
+	}
+
+	var version int64
+	var cond Option
+	if m.version != "" {
+		_record, cond, version, err = m.versionUpdate(_record, byPk)
+		if err != nil {
+			return nil, err
+		}
+		if cond != nil {
+			opt = append(opt, cond)
+		}
 	}
 
-	_record, err = m.hookInput(_record)
+	_record, err = m.hookInput(ctx, _record)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	delete(_record, m.primaryKey)
 	if len(_record) == 0 {
-		return false, errors.New("empty record to update")
+		return nil, errors.New("empty record to update")
 	}
 
-	if m.enableValidator {
-		for _, v := range m.columnValidator {
-			err = v(NewValidOpt(withRow(_record), WithModel(m)))
-			if err != nil {
-				return false, err
-			}
+	if err = m.valid(ctx, _record); err != nil {
+		return nil, err
+	}
+
+	// the FindBy cache of the rows is stale after updated
+	if byPk && cache != nil {
+		if err = cacheDel(ctx, m.cacheKeyPrefix(cast.ToInt64(id))); err != nil {
+			return nil, err
 		}
+	} else if err = m.forgetCache(ctx, opt); err != nil {
+		return nil, err
 	}
 
 	ks, vs := m.recordToKV(_record)
-	opt = append(opt, table(m.table), Field(ks...), Value(vs...))
+	opt = append(opt, table(m.table), Field(ks...), Value(vs...), dialect(m.dialect))
 
 	_sql, args := UpdateBuilder(opt...)
 	kv = append(kv, "sql:", _sql, "args:", vs)
 
-	result, err := exec(m.client, _sql, args...)
+	result, err := exec(ctx, m.writer(), _sql, args...)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	effect, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if effect == 0 && cond != nil {
+		exists, err := m.exists(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrNotFound
+		}
+		return nil, ErrStaleRecord
+	}
+
+	if effect == 0 && byPk && m.strict {
+		// mysql report the changed rows, the row may exist with the same values
+		exists, err := m.exists(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrNotFound
+		}
+	}
+
+	return &UpdateResult{RowsAffected: effect, SQL: _sql, Args: args, Version: version}, nil
+}
+
+// exists check the row of primary key by the write client, without fake delete condition
+func (m *model) exists(ctx context.Context, id interface{}) (bool, error) {
+	opt := []Option{dialect(m.dialect), table(m.table), Field(m.primaryKey), WhereEq(m.primaryKey, id), Limit(1)}
+	if m.tenantKey != "" {
+		opt = append(opt, m.tenantCond(m.tenantKey))
+	}
+	_sql, args := SelectBuilder(opt...)
+	rows, err := query(ctx, m.writer(), _sql, args...)
 	if err != nil {
 		return false, err
 	}
+	return len(rows) > 0, nil
+}
 
-	return effect >= int64(0), nil
+// Increment update field = field + n of the rows matched opt, the validators are skipped
+func (m *model) Increment(field string, n interface{}, opt ...Option) (ok bool, err error) {
+	return m.incr(field, "+", n, opt...)
+}
+
+// Decrement update field = field - n of the rows matched opt, the validators are skipped
+func (m *model) Decrement(field string, n interface{}, opt ...Option) (ok bool, err error) {
+	return m.incr(field, "-", n, opt...)
+}
+
+func (m *model) incr(field string, operator string, n interface{}, opt ...Option) (ok bool, err error) {
+	_m := *m
+	_m.enableValidator = false
+	return _m.Update(Record{field: Expr(quote(field)+" "+operator+" ?", n)}, opt...)
 }
 
 func (m *model) Delete(opt ...Option) (ok bool, err error) {
+	res, err := m.DeleteWithResult(opt...)
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// DeleteWithResult delete and report the affected rows, it's the result of update for fake delete
+func (m *model) DeleteWithResult(opt ...Option) (res *DeleteResult, err error) {
 	if len(opt) == 0 {
-		return false, errors.New("danger, delete query must with some condition")
+		return nil, errors.New("danger, delete query must with some condition")
 	}
 
 	if m.err != nil {
-		return false, m.err
+		return nil, m.err
+	}
+	if _, err = m.tenantId(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.context("Delete")
+	defer cancel()
+
+	opt = m.scopeOpt(opt)
+	if err = m.checkColumns(ctx, opt); err != nil {
+		return nil, err
 	}
 
 	opt = append(opt, table(m.table))
 	if m.fakeDelKey != "" {
-		m.enableValidator = false
-		defer func() {
-			m.enableValidator = true
-		}()
-		return m.Update(map[string]interface{}{m.fakeDelKey: 1}, opt...)
+		_m := m.withCtx(ctx)
+		_m.enableValidator = false
+		_res, err := _m.UpdateWithResult(m.deletedRecord(), append(groupOr(opt), m.aliveCond(m.fakeDelKey))...)
+		if err != nil {
+			return nil, err
+		}
+		return &DeleteResult{RowsAffected: _res.RowsAffected, SQL: _res.SQL, Args: _res.Args}, nil
 	}
 
 	var kv []interface{}
 	defer dbLog("Delete", time.Now(), &err, &kv)
 
-	_sql, args := DeleteBuilder(opt...)
+	if err = m.forgetCache(ctx, opt); err != nil {
+		return nil, err
+	}
+
+	_sql, args := DeleteBuilder(append(opt, dialect(m.dialect))...)
 	kv = append(kv, "slq:", _sql, "args:", args)
 
-	result, err := exec(m.client, _sql, args...)
+	result, err := exec(ctx, m.writer(), _sql, args...)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	effect, err := result.RowsAffected()
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	return effect > int64(0), nil
+	return &DeleteResult{RowsAffected: effect, SQL: _sql, Args: args}, nil
 }
 
 func (m *model) Exec(query string, args ...interface{}) (sql.Result, error) {
-	return m.client.Exec(query, args...)
+	if _, err := m.tenantId(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := m.context("Exec")
+	defer cancel()
+	return m.writer().ExecContext(ctx, query, args...)
 }
 
+// Query the returned rows live with the ctx of model, so the timeout of WithTimeout is not applied
 func (m *model) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	return m.client.Query(query, args...)
+	if _, err := m.tenantId(); err != nil {
+		return nil, err
+	}
+	ctx := m.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return m.writer().QueryContext(ctx, query, args...)
 }
 
-func (m *model) hookInput(record map[string]interface{}) (map[string]interface{}, error) {
+func (m *model) hookInput(ctx context.Context, record map[string]interface{}) (map[string]interface{}, error) {
 	for k, v := range m.columnHook {
 		for field, val := range record {
+			if _, ok := val.(Expression); ok {
+				continue
+			}
 			if k == field {
-				overVal, err := v.Input(record, val)
+				overVal, err := hookInput(ctx, v, record, val)
 				if err != nil {
 					return nil, err
 				}
@@ -330,6 +710,34 @@ func (m *model) hookInput(record map[string]interface{}) (map[string]interface{}
 	return record, nil
 }
 
+func (m *model) hookOutput(ctx context.Context, rows []Row) ([]Row, error) {
+	for k, v := range m.columnHook {
+		for i, r := range rows {
+			for field, val := range r.Data {
+				if k == field {
+					overVal, err := hookOutput(ctx, v, rows[i].Data, val)
+					if err != nil {
+						return nil, err
+					}
+					rows[i].Data[field] = overVal
+				}
+			}
+		}
+	}
+	return rows, nil
+}
+
+// qualify prefix field with the main table (or its alias) when the query joins other tables
+func (m *model) qualify(opts *Options, field string) string {
+	if len(opts.join) == 0 || strings.Contains(field, ".") {
+		return field
+	}
+	if opts.alias != "" {
+		return opts.alias + "." + field
+	}
+	return m.table + "." + field
+}
+
 func (m *model) recordToKV(record map[string]interface{}) (ks []string, vs []interface{}) {
 	for k, v := range record {
 		ks = append(ks, k)
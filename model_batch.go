@@ -0,0 +1,149 @@
+package fly
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// InsertMany insert records by multi rows insert statements in one transaction,
+// the column hooks and validators are applied on each record.
+// consecutive records with the same columns are inserted together, at most batchSize (<= 0 means no limit) rows
+// and the max placeholders of the driver per statement.
+// ids are the generated primary keys in the order of records, nil if the driver can't report them,
+// for mysql they are calculated by LastInsertId and wrong if auto_increment_increment is not 1.
+func (m *model) InsertMany(records []Record, batchSize int) (count int64, ids []int64, err error) {
+	if m.err != nil {
+		return 0, nil, m.err
+	}
+	if len(records) == 0 {
+		return 0, nil, errors.New("empty records to insert")
+	}
+
+	var kv []interface{}
+	defer dbLog("InsertMany", time.Now(), &err, &kv)
+
+	ctx, cancel := m.context("InsertMany")
+	defer cancel()
+
+	var _records []Record
+	for _, v := range records {
+		_record, err := m.insertRecord(ctx, v)
+		if err != nil {
+			return 0, nil, err
+		}
+		_records = append(_records, _record)
+	}
+
+	batches := m.insertBatches(_records, batchSize)
+	kv = append(kv, "rows:", len(_records), "batches:", len(batches))
+
+	insert := func(_m *model) error {
+		for _, batch := range batches {
+			n, _ids, err := _m.insertBatch(ctx, batch)
+			if err != nil {
+				return err
+			}
+			count += n
+			ids = append(ids, _ids...)
+		}
+		return nil
+	}
+
+	if err = m.transaction(ctx, insert); err != nil {
+		return 0, nil, err
+	}
+	if len(ids) != len(_records) {
+		ids = nil
+	}
+
+	return count, ids, nil
+}
+
+// transaction run fn in the transaction of the model (txOf), or a new one of the connection
+func (m *model) transaction(ctx context.Context, fn func(_m *model) error) error {
+	if m.txOf() != nil {
+		return fn(m)
+	}
+	tx, err := BeginWith(ctx, m.client, m.connection)
+	if err != nil {
+		return err
+	}
+	return tx.run(func(tx *Tx) error {
+		return fn(m.withTx(tx))
+	})
+}
+
+func sortedKeys(record Record) []string {
+	ks := make([]string, 0, len(record))
+	for k := range record {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+// insertBatches split records to batches which records have the same columns
+func (m *model) insertBatches(records []Record, batchSize int) (batches [][]Record) {
+	var batch []Record
+	var columns string
+	size := 0
+	for _, v := range records {
+		ks := sortedKeys(v)
+		_columns := strings.Join(ks, ",")
+		if batch == nil || _columns != columns || len(batch) >= size {
+			if batch != nil {
+				batches = append(batches, batch)
+			}
+			batch = nil
+			columns = _columns
+			size = m.dialect.MaxPlaceholders() / len(ks)
+			if batchSize > 0 && batchSize < size {
+				size = batchSize
+			}
+		}
+		batch = append(batch, v)
+	}
+	if batch != nil {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+func (m *model) insertBatch(ctx context.Context, batch []Record) (count int64, ids []int64, err error) {
+	ks := sortedKeys(batch[0])
+	var rows [][]interface{}
+	for _, v := range batch {
+		var row []interface{}
+		for _, k := range ks {
+			row = append(row, v[k])
+		}
+		rows = append(rows, row)
+	}
+
+	_sql, args := InsertBuilder(dialect(m.dialect), table(m.table), Field(ks...), Values(rows...), returning(m.primaryKey))
+
+	if m.dialect.Returning(m.primaryKey) != "" {
+		ids, err = execReturning(ctx, m.writer(), _sql, args...)
+		if err != nil {
+			return 0, nil, err
+		}
+		return int64(len(ids)), ids, nil
+	}
+
+	result, err := exec(ctx, m.writer(), _sql, args...)
+	if err != nil {
+		return 0, nil, err
+	}
+	count, err = result.RowsAffected()
+	if err != nil {
+		return 0, nil, err
+	}
+	if lastId, err := result.LastInsertId(); err == nil {
+		ids = m.dialect.InsertIds(lastId, len(batch))
+	}
+	return count, ids, nil
+}
@@ -0,0 +1,40 @@
+package fly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_insertBatches(t *testing.T) {
+	_m := &model{dialect: SQLite}
+	records := []Record{
+		{"name": "a", "status": 1},
+		{"status": 2, "name": "b"},
+		{"name": "c"},
+		{"name": "d", "status": 3},
+		{"name": "e", "status": 4},
+	}
+	batches := _m.insertBatches(records, 2)
+	assert.Equal(t, [][]Record{records[0:2], records[2:3], records[3:5]}, batches)
+
+	big := make([]Record, 1000)
+	for i := range big {
+		big[i] = Record{"a": i, "b": i}
+	}
+	batches = _m.insertBatches(big, 0)
+	assert.Equal(t, 3, len(batches))
+	assert.Equal(t, 499, len(batches[0]))
+}
+
+func Test_InsertMany(t *testing.T) {
+	count, ids, err := m.InsertMany([]Record{
+		{"name": "Shiryu", "profile": map[string]interface{}{"hobby": "Rozan Shoryuha"}, "role_ids": []int{1}},
+		{"name": "Hyoga", "profile": map[string]interface{}{"hobby": "Diamond Dust"}, "role_ids": []int{2}},
+	}, 100)
+	require.Equal(t, nil, err)
+	assert.Equal(t, int64(2), count)
+	require.Equal(t, 2, len(ids))
+	assert.Equal(t, ids[0]+1, ids[1])
+}
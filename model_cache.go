@@ -8,6 +8,10 @@ import (
 )
 
 func (m *model) cacheKeyPrefix(id int64) string {
+	if m.tenantKey != "" {
+		tenant, _ := m.tenantId()
+		return fmt.Sprintf("%s-%s-%v-%d", m.connection, m.table, tenant, id)
+	}
 	return fmt.Sprintf("%s-%s-%d", m.connection, m.table, id)
 }
 
@@ -20,10 +24,16 @@ func (m *model) FindBy(id int64) *Row {
 	if pk == "" {
 		return &Row{Err: errors.New("primary is not defined")}
 	}
+	if _, err := m.tenantId(); err != nil {
+		return &Row{Err: err}
+	}
+
+	ctx, cancel := m.context("FindBy")
+	defer cancel()
 
 	key := m.cacheKeyPrefix(id)
 
-	c, err := cache.Get(key)
+	c, err := cacheGet(ctx, key)
 	if err != nil {
 		return &Row{Err: err}
 	}
@@ -37,13 +47,13 @@ func (m *model) FindBy(id int64) *Row {
 		return &Row{Data: result}
 	}
 
-	row := m.SelectOne(WhereEq(pk, id))
+	row := m.withCtx(ctx).SelectOne(WhereEq(pk, id))
 	if row.Err == nil && row.Data != nil {
 		c, err := json.Marshal(row.Data)
 		if err != nil {
 			return &Row{Err: err}
 		}
-		err = cache.Set(key, string(c))
+		err = cacheSet(ctx, key, string(c))
 		if err != nil {
 			return &Row{Err: err}
 		}
@@ -57,12 +67,20 @@ func (m *model) UpdateBy(id int64, record Record) (bool, error) {
 	if cache == nil {
 		return false, errors.New("cache instance is nil")
 	}
-	_, err := m.Update(record, WhereEq("id", id))
+	ctx, cancel := m.context("UpdateBy")
+	defer cancel()
+	// update by primary key, so the version of WithVersionColumn is checked
+	_record := make(Record, len(record)+1)
+	for k, v := range record {
+		_record[k] = v
+	}
+	_record[m.primaryKey] = id
+	_, err := m.withCtx(ctx).Update(_record)
 	if err != nil {
 		return false, err
 	}
 	key := m.cacheKeyPrefix(id)
-	err = cache.Del(key)
+	err = cacheDel(ctx, key)
 	if err != nil {
 		return false, err
 	}
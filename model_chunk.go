@@ -0,0 +1,85 @@
+package fly
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Chunk select rows by offset paging, and call fn with each batch until fn return error,
+// the rows are ordered by primary key if opt has no order by
+func (m *model) Chunk(size int, fn func(rows *Rows) error, opt ...Option) error {
+	if size <= 0 {
+		return errors.New("chunk size must be greater than 0")
+	}
+
+	opt = m.scopeOpt(opt)
+	opts := new(Options)
+	for _, o := range opt {
+		o(opts)
+	}
+	if len(opts.orderBy) == 0 {
+		opt = append(opt, OrderByAsc(m.primaryKey))
+	}
+
+	for page := 1; ; page++ {
+		rows := m.Select(append(opt, Pagination(page, size)...)...)
+		if rows.Err != nil {
+			return rows.Err
+		}
+		if len(rows.List) == 0 {
+			return nil
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+		if len(rows.List) < size {
+			return nil
+		}
+	}
+}
+
+// ChunkById select rows by primary key (where pk > last id order by pk),
+// it's faster than Chunk for large table and not affected by the rows changed in fn
+func (m *model) ChunkById(size int, fn func(rows *Rows) error, opt ...Option) error {
+	if size <= 0 {
+		return errors.New("chunk size must be greater than 0")
+	}
+
+	pk := m.primaryKey
+	opts := new(Options)
+	for _, o := range opt {
+		o(opts)
+	}
+	// qualified by the table (or alias) in case of join
+	field := m.qualify(opts, pk)
+	// keyset paging must be ordered by primary key only
+	opt = append(opt, Limit(size), func(opts *Options) {
+		opts.orderBy = []order{{field: field}}
+	})
+
+	var lastId interface{}
+	for {
+		_opt := opt
+		if lastId != nil {
+			// grouped, so the id condition applies to all the or conditions of opt
+			_opt = append(groupOr(opt), WhereGt(field, lastId))
+		}
+		rows := m.Select(_opt...)
+		if rows.Err != nil {
+			return rows.Err
+		}
+		if len(rows.List) == 0 {
+			return nil
+		}
+		id, ok := rows.List[len(rows.List)-1].Data[pk]
+		if !ok {
+			return errors.New("chunk by id must select the primary key " + pk)
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+		if len(rows.List) < size {
+			return nil
+		}
+		lastId = id
+	}
+}
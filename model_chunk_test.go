@@ -0,0 +1,48 @@
+package fly
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ChunkByIdOr(t *testing.T) {
+	_m := sqliteModel(t, "item", `
+CREATE TABLE "item" (
+    id  integer not null primary key autoincrement,
+    uid integer default 0 not null,
+    a   integer default 0 not null,
+    b   integer default 0 not null
+)`)
+	_, err := New("user", WithConn(sqliteConn(t))).Exec(`
+CREATE TABLE "user" (
+    id   integer not null primary key autoincrement,
+    name varchar(64) default '' not null
+)`)
+	require.Equal(t, nil, err)
+	_, err = New("user", WithConn(sqliteConn(t))).Insert(Record{"name": "Seiya"})
+	require.Equal(t, nil, err)
+	for _, v := range []Record{{"a": 0, "b": 1}, {"a": 1, "b": 4}, {"a": 0, "b": 2}, {"a": 1, "b": 3}} {
+		v["uid"] = 1
+		_, err := _m.Insert(v)
+		require.Equal(t, nil, err)
+	}
+
+	// a = 0 or b = 4 => 1, 2, 3, the id condition applies to both branches
+	var ids []string
+	chunks := 0
+	err = _m.ChunkById(1, func(rows *Rows) error {
+		chunks++
+		if chunks > 5 {
+			return errors.New("chunk loop")
+		}
+		for _, v := range rows.List {
+			ids = append(ids, v.GetString("id"))
+		}
+		return nil
+	}, Field("item.id", "user.name"), Join("user", On("user.id", "=", "item.uid")), WhereEq("a", 0), WhereOrEq("b", 4))
+	require.Equal(t, nil, err)
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+}
@@ -0,0 +1,157 @@
+package fly
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// UnknownColumnError the identifier is not a column of the model (WithColumns, WithSchemaColumns)
+type UnknownColumnError struct {
+	Table  string
+	Column string
+}
+
+func (e *UnknownColumnError) Error() string {
+	return fmt.Sprintf("fly: unknown column %s of table %s", e.Column, e.Table)
+}
+
+// columnSet the column whitelist shared by the copies of model
+type columnSet struct {
+	mu     sync.Mutex
+	names  map[string]bool
+	schema bool
+	loaded bool
+}
+
+// WithColumns only the columns can be used as the identifiers of the model (fields, conditions, order by, group by and record keys),
+// others are rejected with *UnknownColumnError, the identifiers of raw sql, sub queries and other tables are not checked.
+func WithColumns(columns ...string) With {
+	return func(b *model) {
+		if b.columns == nil {
+			b.columns = &columnSet{names: map[string]bool{}}
+		}
+		for _, v := range columns {
+			b.columns.names[v] = true
+		}
+	}
+}
+
+// WithSchemaColumns the same as WithColumns, the columns are read from the table when first used
+func WithSchemaColumns() With {
+	return func(b *model) {
+		if b.columns == nil {
+			b.columns = &columnSet{names: map[string]bool{}}
+		}
+		b.columns.schema = true
+	}
+}
+
+func (m *model) loadColumns(ctx context.Context) (map[string]bool, error) {
+	c := m.columns
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.schema || c.loaded {
+		return c.names, nil
+	}
+
+	_sql, args := SelectBuilder(table(m.table), database(m.database), dialect(m.dialect), WhereRaw("1 = 0"))
+	rows, err := m.reader().QueryContext(ctx, _sql, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "fly.loadColumns err")
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, errors.Wrap(err, "fly.loadColumns err")
+	}
+	for _, v := range columns {
+		c.names[v] = true
+	}
+	c.loaded = true
+	return c.names, nil
+}
+
+// checkColumns check the identifiers of opt and the extra columns (e.g. record keys) by the column whitelist
+func (m *model) checkColumns(ctx context.Context, opt []Option, extra ...string) error {
+	if m.columns == nil {
+		return nil
+	}
+	names, err := m.loadColumns(ctx)
+	if err != nil {
+		return err
+	}
+
+	opts := new(Options)
+	for _, o := range opt {
+		o(opts)
+	}
+
+	// the aliases of fields (include FieldRaw) can be used by order by and having
+	aliases := map[string]bool{}
+	for _, v := range opts.field {
+		if i := strings.LastIndex(strings.ToLower(v), " as "); i >= 0 {
+			aliases[unquote(strings.TrimSpace(v[i+4:]))] = true
+		}
+	}
+
+	var idents []string
+	for _, v := range opts.columns {
+		idents = append(idents, asSplit.Split(strings.TrimSpace(v), 2)[0])
+	}
+	for _, v := range opts.orderBy {
+		idents = append(idents, v.field)
+	}
+	idents = append(idents, whereColumns(opts.where)...)
+	idents = append(idents, whereColumns(opts.having)...)
+	for _, v := range opts.join {
+		// the unqualified columns of on may belong to the joined table
+		for _, c := range whereColumns(v.on) {
+			if len(splitIdent(strings.TrimSpace(c))) > 1 {
+				idents = append(idents, c)
+			}
+		}
+	}
+	if opts.from != nil {
+		// the columns of a derived table are unknown
+		idents = nil
+	}
+	idents = append(idents, extra...)
+
+	for _, v := range idents {
+		parts := splitIdent(strings.TrimSpace(v))
+		col := unquote(parts[len(parts)-1])
+		if col == "*" {
+			continue
+		}
+		if len(parts) > 1 {
+			qualifier := unquote(parts[len(parts)-2])
+			if qualifier != m.table && qualifier != opts.alias {
+				continue
+			}
+		} else if aliases[col] {
+			continue
+		}
+		if !names[col] && col != m.primaryKey {
+			return &UnknownColumnError{Table: m.table, Column: v}
+		}
+	}
+	return nil
+}
+
+// whereColumns the fields of conditions, include the compared columns (On, WhereColumn)
+func whereColumns(condition []where) (columns []string) {
+	for _, v := range condition {
+		if v.field != "" {
+			columns = append(columns, v.field)
+		}
+		if col, ok := v.value.(column); ok {
+			columns = append(columns, string(col))
+		}
+		columns = append(columns, whereColumns(v.sub)...)
+	}
+	return columns
+}
@@ -0,0 +1,68 @@
+package fly
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, "`name`", quote("name"))
+	assert.Equal(t, "`u`.`name`", quote("u.name"))
+	assert.Equal(t, "`u`.*", quote("u.*"))
+	assert.Equal(t, "`u`.`name`", quote("`u`.`name`"))
+	assert.Equal(t, "`a.b`.`c`", quote("`a.b`.c"))
+	assert.Equal(t, "`id``; drop table user; --`", quote("id`; drop table user; --"))
+	assert.Equal(t, "`id desc, (select 1)`", quote("id desc, (select 1)"))
+
+	sql, _ := SelectBuilder(table("user"), WhereFindInSet("role_ids", 1), OrderByDesc("id` desc, sleep(1) #"))
+	assert.Equal(t, "select * from `user` where find_in_set(?, `role_ids`) order by `id`` desc, sleep(1) #` desc", sql)
+
+	sql, _ = SelectBuilder(table("user"), AggregateSum("a) from u; drop table u; --"))
+	assert.Equal(t, "select sum(`a) from u; drop table u; --`) as aggregate from `user`", sql)
+	sql, _ = SelectBuilder(table("user"), AggregateMax("u.score"), AggregateCount("*"))
+	assert.Equal(t, "select max(`u`.`score`) as aggregate, count(*) as count from `user`", sql)
+
+	// postgres
+	sql, _ = SelectBuilder(dialect(Postgres), table("user"), OrderByDesc(`id" desc; drop table "user"; --`))
+	assert.Equal(t, `select * from "user" order by "id"" desc; drop table ""user""; --" desc`, sql)
+	sql, _ = SelectBuilder(dialect(Postgres), table("user"), OrderByDesc("id` desc"))
+	assert.Equal(t, `select * from "user" order by "id`+"`"+` desc" desc`, sql)
+	sql, args := SelectBuilder(dialect(Postgres), table("user"), WhereEq("it's", "a'b`?"), OrderByDesc(`na'me`))
+	assert.Equal(t, `select * from "user" where "it's" = $1 order by "na'me" desc`, sql)
+	assert.Equal(t, []interface{}{"a'b`?"}, args)
+	assert.Equal(t, "select 'a``b', \"c\" from \"t\" where \"id\" = $1", Postgres.Rebind("select 'a``b', `c` from `t` where `id` = ?"))
+}
+
+func Test_checkColumns(t *testing.T) {
+	_m := New("user", WithColumns("name", "status"))
+	ctx := context.Background()
+
+	assert.Equal(t, nil, _m.checkColumns(ctx, []Option{
+		Alias("u"),
+		Field("u.id", "name as n"),
+		FieldRaw("count(*) as total"),
+		WhereEq("u.status", 1),
+		LeftJoin("level as l", On("l.user_id", "=", "u.id"), On("l.status", "=", "status")),
+		OrderByDesc("total"),
+		GroupBy("name"),
+	}, "status"))
+
+	err := _m.checkColumns(ctx, []Option{Alias("u"), LeftJoin("level as l", On("l.id", "=", "u.level_id"))})
+	assert.Equal(t, &UnknownColumnError{Table: "user", Column: "u.level_id"}, err)
+
+	err = _m.checkColumns(ctx, []Option{LeftJoin("level as l", OrOn("user.password", "=", "l.password"))})
+	assert.Equal(t, &UnknownColumnError{Table: "user", Column: "user.password"}, err)
+
+	err = _m.checkColumns(ctx, []Option{WhereEq("status", 1), OrderByAsc("password")})
+	assert.Equal(t, &UnknownColumnError{Table: "user", Column: "password"}, err)
+
+	err = _m.checkColumns(ctx, []Option{AggregateSum("status"), AggregateAvg("password")})
+	assert.Equal(t, &UnknownColumnError{Table: "user", Column: "password"}, err)
+
+	err = _m.checkColumns(ctx, nil, "name", "user.role")
+	assert.Equal(t, &UnknownColumnError{Table: "user", Column: "user.role"}, err)
+
+	assert.Equal(t, nil, New("user").checkColumns(ctx, []Option{OrderByAsc("password")}))
+}
@@ -0,0 +1,85 @@
+package fly
+
+import (
+	"github.com/pkg/errors"
+)
+
+// FirstOrCreate select the row matched where, or insert where with defaults if not found.
+// it runs in a transaction and the matched row is locked (for update) until the end of it,
+// the where columns should be a unique index, otherwise the concurrent creating may insert duplicate rows.
+func (m *model) FirstOrCreate(where Record, defaults Record) (row *Row, created bool, err error) {
+	return m.firstOr("FirstOrCreate", where, defaults, func(_m *model, row *Row) (*Row, error) {
+		return row, nil
+	})
+}
+
+// UpdateOrCreate update the row matched where with values, or insert where with values if not found,
+// the same as FirstOrCreate it runs in a transaction with row lock
+func (m *model) UpdateOrCreate(where Record, values Record) (row *Row, created bool, err error) {
+	return m.firstOr("UpdateOrCreate", where, values, func(_m *model, row *Row) (*Row, error) {
+		record := Record{}
+		for k, v := range values {
+			record[k] = v
+		}
+		delete(record, _m.primaryKey)
+		if len(record) == 0 {
+			return row, nil
+		}
+		id := row.Data[_m.primaryKey]
+		if _, err := _m.Update(record, WhereEq(_m.primaryKey, id)); err != nil {
+			return nil, err
+		}
+		row = _m.SelectOne(WhereEq(_m.primaryKey, id))
+		return row, row.Err
+	})
+}
+
+// firstOr select the row matched where with lock, found is called if it exists, otherwise where with values is inserted
+func (m *model) firstOr(op string, where Record, values Record, found func(_m *model, row *Row) (*Row, error)) (row *Row, created bool, err error) {
+	if m.err != nil {
+		return nil, false, m.err
+	}
+	if len(where) == 0 {
+		return nil, false, errors.New("danger, " + op + " must with some condition")
+	}
+
+	ctx, cancel := m.context(op)
+	defer cancel()
+
+	var opt []Option
+	for _, k := range sortedKeys(where) {
+		opt = append(opt, WhereEq(k, where[k]))
+	}
+	opt = append(opt, lock(lockUpdate))
+
+	err = m.withCtx(ctx).transaction(ctx, func(_m *model) error {
+		row = _m.SelectOne(opt...)
+		if row.Err == nil {
+			var err error
+			row, err = found(_m, row)
+			return err
+		}
+		if row.Err != ErrNotFound {
+			return row.Err
+		}
+
+		record := Record{}
+		for k, v := range values {
+			record[k] = v
+		}
+		for k, v := range where {
+			record[k] = v
+		}
+		id, err := _m.Insert(record)
+		if err != nil {
+			return err
+		}
+		created = true
+		row = _m.SelectOne(WhereEq(_m.primaryKey, id))
+		return row.Err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return row, created, nil
+}
@@ -1,6 +1,7 @@
 package fly
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -16,6 +17,13 @@ type HasOpts struct {
 	LocalKey   string
 	ForeignKey string
 	OtherKeys  []string
+	// FakeDelKey, DeletedAt the soft delete key of the related table, the soft deleted rows are not loaded
+	FakeDelKey string
+	DeletedAt  string
+	// TenantKey the tenant column of the related table, it's the same as the model of WithTenant by default
+	TenantKey string
+	// NoTenant the related table is shared by all tenants (e.g. a lookup table), it's not filtered by tenant
+	NoTenant bool
 }
 
 var space = regexp.MustCompile(`\s+`)
@@ -42,7 +50,31 @@ func otherKeys(key []string) (rel []string, err error) {
 	return rel, nil
 }
 
-func (m *model) hasOneData(rows []Row, opt HasOpts) ([]Row, error) {
+// relation the model of related table, it joins the transaction of m on the same connection
+func (m *model) relation(ctx context.Context, opt HasOpts) Model {
+	with := []With{WithConn(opt.Conn)}
+	if opt.FakeDelKey != "" {
+		with = append(with, WithFakeDelKey(opt.FakeDelKey))
+	}
+	if opt.DeletedAt != "" {
+		with = append(with, WithDeletedAt(opt.DeletedAt))
+	}
+	if opt.NoTenant {
+		opt.TenantKey = ""
+	} else if opt.TenantKey == "" {
+		opt.TenantKey = m.tenantKey
+	}
+	if opt.TenantKey != "" {
+		with = append(with, WithTenant(opt.TenantKey))
+	}
+	var rel Model = New(opt.Table, with...)
+	if tx := m.txOf(); tx != nil && tx.conn == opt.Conn {
+		rel = rel.WithTx(tx)
+	}
+	return rel.Ctx(ctx)
+}
+
+func (m *model) hasOneData(ctx context.Context, rows []Row, opt HasOpts) ([]Row, error) {
 	otherKeys, err := otherKeys(opt.OtherKeys)
 	if err != nil {
 		return nil, err
@@ -61,7 +93,7 @@ func (m *model) hasOneData(rows []Row, opt HasOpts) ([]Row, error) {
 
 	opt.OtherKeys = append(opt.OtherKeys, opt.ForeignKey)
 
-	_rows := New(opt.Table, WithConn(opt.Conn)).Select(Field(opt.OtherKeys...), WhereIn(opt.ForeignKey, localKeys))
+	_rows := m.relation(ctx, opt).Select(Field(opt.OtherKeys...), WhereIn(opt.ForeignKey, localKeys))
 	if _rows.Err != nil {
 		return nil, errors.Wrap(_rows.Err, "hasOne err")
 	}
@@ -87,7 +119,7 @@ func (m *model) hasOneData(rows []Row, opt HasOpts) ([]Row, error) {
 	return rows, nil
 }
 
-func (m *model) hasManyData(rows []Row, opt HasOpts) ([]Row, error) {
+func (m *model) hasManyData(ctx context.Context, rows []Row, opt HasOpts) ([]Row, error) {
 	otherKeys, err := otherKeys(opt.OtherKeys)
 	if err != nil {
 		return nil, err
@@ -106,7 +138,7 @@ func (m *model) hasManyData(rows []Row, opt HasOpts) ([]Row, error) {
 
 	opt.OtherKeys = append(opt.OtherKeys, opt.ForeignKey)
 
-	_rows := New(opt.Table, WithConn(opt.Conn)).Select(Field(opt.OtherKeys...), WhereIn(opt.ForeignKey, localKeys))
+	_rows := m.relation(ctx, opt).Select(Field(opt.OtherKeys...), WhereIn(opt.ForeignKey, localKeys))
 	if _rows.Err != nil {
 		return nil, errors.Wrap(_rows.Err, "hasOne err")
 	}
@@ -0,0 +1,193 @@
+package fly
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+type Page struct {
+	List     []Row `json:"list"`
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+	LastPage int   `json:"last_page"`
+}
+
+func (p *Page) Binding(dest interface{}) error {
+	return (&Rows{List: p.List}).Binding(dest)
+}
+
+// Paginate select the rows of page and count the total rows with the same options
+func (m *model) Paginate(page, size int, opt ...Option) (*Page, error) {
+	if size <= 0 {
+		return nil, errors.New("page size must be greater than 0")
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	total, err := m.Count(opt...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Page{
+		List:     []Row{},
+		Total:    total,
+		Page:     page,
+		PageSize: size,
+		LastPage: int((total + int64(size) - 1) / int64(size)),
+	}
+	if result.LastPage < 1 {
+		result.LastPage = 1
+	}
+	if total == 0 || page > result.LastPage {
+		return result, nil
+	}
+
+	rows := m.Select(append(opt, Pagination(page, size)...)...)
+	if rows.Err != nil {
+		return nil, rows.Err
+	}
+	result.List = append(result.List, rows.List...)
+	return result, nil
+}
+
+type CursorPage struct {
+	List       []Row  `json:"list"`
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+}
+
+func (p *CursorPage) Binding(dest interface{}) error {
+	return (&Rows{List: p.List}).Binding(dest)
+}
+
+// PaginateCursor keyset pagination by the order by columns of opt (primary key is appended as the tie-breaker),
+// pass the NextCursor of previous page to get the next page, empty cursor for the first page.
+// the order by columns must be selected.
+func (m *model) PaginateCursor(cursor string, size int, opt ...Option) (*CursorPage, error) {
+	if size <= 0 {
+		return nil, errors.New("page size must be greater than 0")
+	}
+
+	opt = m.scopeOpt(opt)
+	opts := new(Options)
+	for _, o := range opt {
+		o(opts)
+	}
+	orders := opts.orderBy
+	hasPk := false
+	for _, v := range orders {
+		if columnName(v.field) == m.primaryKey {
+			hasPk = true
+		}
+	}
+	if !hasPk {
+		orders = append(orders, order{field: m.primaryKey})
+		opt = append(opt, OrderByAsc(m.primaryKey))
+	}
+
+	if cursor != "" {
+		values, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != len(orders) {
+			return nil, ErrInvalidCursor
+		}
+		// grouped, so the seek condition applies to all the or conditions of opt
+		opt = append(groupOr(opt), seekAfter(orders, values))
+	}
+
+	rows := m.Select(append(opt, Limit(size+1))...)
+	if rows.Err != nil {
+		return nil, rows.Err
+	}
+
+	result := &CursorPage{List: []Row{}, PageSize: size}
+	list := rows.List
+	if len(list) > size {
+		result.HasMore = true
+		list = list[:size]
+	}
+	result.List = append(result.List, list...)
+
+	if result.HasMore {
+		last := list[len(list)-1]
+		var values []interface{}
+		for _, v := range orders {
+			val, ok := last.Data[columnName(v.field)]
+			if !ok {
+				return nil, errors.New("cursor paginate must select the order by field " + v.field)
+			}
+			values = append(values, val)
+		}
+		next, err := encodeCursor(values)
+		if err != nil {
+			return nil, err
+		}
+		result.NextCursor = next
+	}
+
+	return result, nil
+}
+
+// seekAfter (a > ?) or (a = ? and b > ?) ..., the operator is < for desc order
+func seekAfter(orders []order, values []interface{}) Option {
+	var groups []Option
+	for i, v := range orders {
+		var cond []Option
+		for j := 0; j < i; j++ {
+			cond = append(cond, WhereEq(orders[j].field, values[j]))
+		}
+		operator := ">"
+		if v.desc {
+			operator = "<"
+		}
+		cond = append(cond, Where(v.field, operator, values[i]))
+		if i == 0 {
+			groups = append(groups, WhereGroup(cond...))
+		} else {
+			groups = append(groups, WhereOrGroup(cond...))
+		}
+	}
+	return WhereGroup(groups...)
+}
+
+// columnName the key of field in row, e.g. u.id => id
+func columnName(field string) string {
+	if i := strings.LastIndex(field, "."); i >= 0 {
+		return field[i+1:]
+	}
+	return field
+}
+
+func encodeCursor(values []interface{}) (string, error) {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(cursor string) ([]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var values []interface{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return values, nil
+}
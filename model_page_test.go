@@ -0,0 +1,85 @@
+package fly
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_seekAfter(t *testing.T) {
+	sql, args := SelectBuilder(
+		table("user"),
+		WhereEq("status", 1),
+		seekAfter([]order{{field: "score", desc: true}, {field: "id"}}, []interface{}{90, 7}),
+	)
+	assert.Equal(t, "select * from `user` where `status` = ? and ((`score` < ?) or (`score` = ? and `id` > ?))", sql)
+	assert.Equal(t, []interface{}{1, 90, 90, 7}, args)
+}
+
+func Test_cursorToken(t *testing.T) {
+	token, err := encodeCursor([]interface{}{"2023-01-01", 9007199254740993})
+	assert.Equal(t, nil, err)
+
+	values, err := decodeCursor(token)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []interface{}{"2023-01-01", json.Number("9007199254740993")}, values)
+
+	_, err = decodeCursor("not a cursor")
+	assert.Equal(t, ErrInvalidCursor, err)
+}
+
+func Test_Paginate(t *testing.T) {
+	page, err := m.Paginate(1, 2, WhereGe("id", 1), OrderByDesc("id"))
+	require.Equal(t, nil, err)
+	count, err := m.Count(WhereGe("id", 1))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, count, page.Total)
+
+	var ids []int64
+	cursor := ""
+	for {
+		p, err := m.PaginateCursor(cursor, 2, Field("id", "name"), OrderByDesc("id"))
+		require.Equal(t, nil, err)
+		var users []User
+		assert.Equal(t, nil, p.Binding(&users))
+		for _, u := range users {
+			ids = append(ids, u.ID)
+		}
+		if !p.HasMore {
+			break
+		}
+		cursor = p.NextCursor
+	}
+	assert.Equal(t, count, int64(len(ids)))
+}
+
+func Test_PaginateCursorOr(t *testing.T) {
+	_m := sqliteModel(t, "item", `
+CREATE TABLE "item" (
+    id integer not null primary key autoincrement,
+    a  integer default 0 not null,
+    b  integer default 0 not null
+)`)
+	for _, v := range []Record{{"a": 0, "b": 1}, {"a": 1, "b": 4}, {"a": 0, "b": 2}, {"a": 1, "b": 3}} {
+		_, err := _m.Insert(v)
+		require.Equal(t, nil, err)
+	}
+
+	// a = 0 or b = 4 => 1, 2, 3, the seek condition applies to both branches
+	var ids []string
+	cursor := ""
+	for i := 0; i < 5; i++ {
+		p, err := _m.PaginateCursor(cursor, 1, WhereEq("a", 0), WhereOrEq("b", 4))
+		require.Equal(t, nil, err)
+		for _, v := range p.List {
+			ids = append(ids, v.GetString("id"))
+		}
+		if !p.HasMore {
+			break
+		}
+		cursor = p.NextCursor
+	}
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+}
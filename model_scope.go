@@ -0,0 +1,74 @@
+package fly
+
+import (
+	"github.com/pkg/errors"
+)
+
+type scope struct {
+	name string
+	opt  []Option
+}
+
+// Scope return a copy of the model with the local scope (WithScope) applied to every Select, Count, Update and Delete
+func (m *model) Scope(name string) Model {
+	_m := *m
+	opt, ok := m.scopes[name]
+	if !ok {
+		_m.err = errors.New("undefined scope " + name)
+		return &_m
+	}
+	_m.scoped = append(append([][]Option{}, m.scoped...), opt)
+	return &_m
+}
+
+// scopeOpt prepend the options of scopes to opt, so the options of query take precedence (e.g. Field).
+// the conditions of each scope and opt are grouped if they have or, so all of them are applied.
+// it's applied only once, the query of sub select (Count with group by) is skipped.
+// the scopes are not applied to a derived table (FromSub), but the tenant condition is still added to its sub queries
+func (m *model) scopeOpt(opt []Option) []Option {
+	if len(m.globalScopes) == 0 && len(m.scoped) == 0 && m.tenantKey == "" {
+		return opt
+	}
+	opts := new(Options)
+	for _, o := range opt {
+		o(opts)
+	}
+	if opts.scoped {
+		return opt
+	}
+	if opts.from != nil {
+		if m.tenantKey == "" {
+			return opt
+		}
+		return append(opt[:len(opt):len(opt)], m.tenantSub, func(opts *Options) {
+			opts.scoped = true
+		})
+	}
+
+	unscoped := map[string]bool{}
+	for _, v := range opts.unscoped {
+		unscoped[v] = true
+	}
+
+	var _opt []Option
+	if m.tenantKey != "" {
+		// the tenant condition can't be disabled by WithoutScope
+		_opt = append(_opt, m.tenantCond(m.qualify(opts, m.tenantKey)))
+	}
+	for _, v := range m.globalScopes {
+		if unscoped["*"] || unscoped[v.name] {
+			continue
+		}
+		_opt = append(_opt, groupOr(v.opt)...)
+	}
+	for _, v := range m.scoped {
+		_opt = append(_opt, groupOr(v)...)
+	}
+	_opt = append(_opt, groupOr(opt)...)
+	if m.tenantKey != "" {
+		_opt = append(_opt, m.tenantSub)
+	}
+	return append(_opt, func(opts *Options) {
+		opts.scoped = true
+	})
+}
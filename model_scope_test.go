@@ -0,0 +1,77 @@
+package fly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scopeModel(t *testing.T, with ...With) Model {
+	_m := sqliteModel(t, "post", `
+CREATE TABLE "post" (
+    id     integer not null primary key autoincrement,
+    uid    integer default 0 not null,
+    title  varchar(64) default '' not null,
+    status integer default 0 not null,
+    views  integer default 0 not null,
+    pinned integer default 0 not null
+)`, with...)
+	for _, v := range []Record{
+		{"uid": 1, "title": "a", "status": 1, "views": 200},
+		{"uid": 1, "title": "b", "status": 0, "views": 300},
+		{"uid": 2, "title": "c", "status": 1, "pinned": 1},
+		{"uid": 2, "title": "d", "status": 0, "pinned": 1},
+		{"uid": 3, "title": "e", "status": 1},
+	} {
+		_, err := _m.Insert(v)
+		require.Equal(t, nil, err)
+	}
+	return _m
+}
+
+func Test_GlobalScope(t *testing.T) {
+	_m := scopeModel(t, WithGlobalScope("pub", WhereEq("status", 1)), WithGlobalScope("latest", OrderByDesc("id")))
+
+	assert.Equal(t, []string{"c", "a"}, titles(t, _m.Select(WhereEq("uid", 1), WhereOrEq("uid", 2))))
+	assert.Equal(t, []string{"a", "c"}, titles(t, _m.Select(WhereEq("uid", 1), WhereOrEq("uid", 2), WithoutScope("latest"), OrderByAsc("id"))))
+	assert.Equal(t, 4, len(titles(t, _m.Select(WhereEq("uid", 1), WhereOrEq("uid", 2), WithoutScope()))))
+	assert.Equal(t, []string{"c", "a"}, titles(t, _m.Select(WhereRaw("uid = 1 or uid = 2"))))
+	count, err := _m.Count(WhereEq("uid", 1), WhereOrEq("uid", 2))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(2), count)
+
+	// the unpublished rows b and d are not updated or deleted by the or branch
+	res, err := _m.UpdateWithResult(Record{"views": 1}, WhereEq("uid", 1), WhereOrEq("uid", 2))
+	require.Equal(t, nil, err)
+	assert.Equal(t, int64(2), res.RowsAffected)
+	assert.Equal(t, "300", _m.SelectOne(WhereEq("title", "b"), WithoutScope()).GetString("views"))
+
+	del, err := _m.DeleteWithResult(WhereEq("uid", 2), WhereOrEq("uid", 3))
+	require.Equal(t, nil, err)
+	assert.Equal(t, int64(2), del.RowsAffected)
+	assert.Equal(t, []string{"a", "b", "d"}, titles(t, _m.Select(WithoutScope(), OrderByAsc("id"))))
+}
+
+func Test_LocalScope(t *testing.T) {
+	_m := scopeModel(t,
+		WithGlobalScope("pub", WhereEq("status", 1)),
+		WithScope("hot", WhereGt("views", 100), WhereOrEq("pinned", 1)),
+		WithScope("user1", WhereEq("uid", 1)),
+	)
+
+	// status = 1 and (views > 100 or pinned = 1)
+	assert.Equal(t, []string{"a", "c"}, titles(t, _m.Scope("hot").Select(OrderByAsc("id"))))
+	assert.Equal(t, []string{"a"}, titles(t, _m.Scope("hot").Scope("user1").Select()))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, titles(t, _m.Scope("hot").Select(WithoutScope(), OrderByAsc("id"))))
+	count, err := _m.Count()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(3), count)
+
+	ok, err := _m.Scope("hot").Delete(WhereEq("uid", 2), WhereOrEq("uid", 3))
+	require.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, []string{"a", "b", "d", "e"}, titles(t, _m.Select(WithoutScope(), OrderByAsc("id"))))
+
+	assert.NotNil(t, _m.Scope("cold").Select().Err)
+}
@@ -0,0 +1,127 @@
+package fly
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// ErrNoTenant the model of WithTenant is used without tenant id
+var ErrNoTenant = errors.New("tenant is required")
+
+type tenantKey struct{}
+
+// TenantContext return a copy of ctx with the tenant id, which is used by the models of WithTenant
+func TenantContext(ctx context.Context, id interface{}) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// TenantFromContext the tenant id of ctx
+func TenantFromContext(ctx context.Context) (interface{}, bool) {
+	id := ctx.Value(tenantKey{})
+	return id, id != nil
+}
+
+// Tenant return a copy of the model with the tenant id, the same as Ctx(TenantContext(ctx, id))
+func (m *model) Tenant(id interface{}) Model {
+	ctx := m.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return m.withCtx(TenantContext(ctx, id))
+}
+
+// tenantId the tenant id of model ctx, nil if the model is not WithTenant
+func (m *model) tenantId() (interface{}, error) {
+	if m.tenantKey == "" {
+		return nil, nil
+	}
+	if m.ctx != nil {
+		if id, ok := TenantFromContext(m.ctx); ok && cast.ToString(id) != "" {
+			return id, nil
+		}
+	}
+	return nil, ErrNoTenant
+}
+
+// tenantCond the condition of tenant column, nothing is matched without tenant id
+func (m *model) tenantCond(field string) Option {
+	id, err := m.tenantId()
+	if err != nil {
+		return WhereRaw("1 = 0")
+	}
+	return WhereEq(field, id)
+}
+
+// tenantRecord return a copy of record with the tenant id if fill, the record of other tenant is rejected
+func (m *model) tenantRecord(record Record, fill bool) (Record, error) {
+	id, err := m.tenantId()
+	if err != nil || id == nil {
+		return record, err
+	}
+	if v, ok := record[m.tenantKey]; ok {
+		if cast.ToString(v) != cast.ToString(id) {
+			return nil, errors.Errorf("the %s of record %v is not the tenant %v", m.tenantKey, v, id)
+		}
+		return record, nil
+	}
+	if !fill {
+		return record, nil
+	}
+	_record := make(Record, len(record)+1)
+	for k, v := range record {
+		_record[k] = v
+	}
+	_record[m.tenantKey] = id
+	return _record, nil
+}
+
+// tenantSub add the tenant condition to the sub queries (derived table, union, cte and where or field sub query)
+// of the model table, so they can't read the rows of other tenant
+func (m *model) tenantSub(opts *Options) {
+	if opts.from != nil {
+		opts.from = &subField{alias: opts.from.alias, query: m.tenantQuery(opts.from.query)}
+	}
+	for i, v := range opts.fieldSub {
+		opts.fieldSub[i].query = m.tenantQuery(v.query)
+	}
+	for i, v := range opts.with {
+		opts.with[i].query = m.tenantQuery(v.query)
+	}
+	for i, v := range opts.union {
+		opts.union[i].query = m.tenantQuery(v.query)
+	}
+	opts.where = m.tenantWhere(opts.where)
+	opts.having = m.tenantWhere(opts.having)
+}
+
+// tenantQuery the sub query with the tenant condition if it selects from the model table
+func (m *model) tenantQuery(q subQuery) subQuery {
+	return append(groupOr(q), func(opts *Options) {
+		// the query scoped by the model already has the condition
+		if opts.scoped {
+			return
+		}
+		if opts.table == m.table && opts.from == nil {
+			m.tenantCond(m.qualify(opts, m.tenantKey))(opts)
+		}
+		m.tenantSub(opts)
+	})
+}
+
+// tenantWhere a copy of conditions with the sub queries of tenantQuery
+func (m *model) tenantWhere(conds []where) []where {
+	if len(conds) == 0 {
+		return conds
+	}
+	_conds := make([]where, 0, len(conds))
+	for _, v := range conds {
+		if q, ok := v.value.(subQuery); ok {
+			v.value = m.tenantQuery(q)
+		}
+		v.sub = m.tenantWhere(v.sub)
+		_conds = append(_conds, v)
+	}
+	return _conds
+}
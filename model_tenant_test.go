@@ -0,0 +1,171 @@
+package fly
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tenantModel(t *testing.T) Model {
+	_m := sqliteModel(t, "post", `
+CREATE TABLE "post" (
+    id        integer not null primary key autoincrement,
+    tenant_id integer default 0 not null,
+    uid       integer default 0 not null,
+    title     varchar(64) default '' not null
+)`, WithTenant("tenant_id"), HasMany(HasOpts{Conn: sqliteConn(t), Table: "comment", ForeignKey: "post_id", OtherKeys: []string{"body"}}))
+	_, err := New("comment", WithConn(sqliteConn(t))).Exec(`
+CREATE TABLE "comment" (
+    id        integer not null primary key autoincrement,
+    tenant_id integer default 0 not null,
+    post_id   integer default 0 not null,
+    body      varchar(64) default '' not null
+)`)
+	require.Equal(t, nil, err)
+
+	// id 1, 2 of tenant 7 and id 3, 4 of tenant 8
+	for i, v := range []string{"a", "b", "c", "d"} {
+		_, err := _m.Tenant(7 + i/2).Insert(Record{"uid": i%2 + 1, "title": v})
+		require.Equal(t, nil, err)
+	}
+	return _m
+}
+
+func Test_TenantFailClosed(t *testing.T) {
+	_m := tenantModel(t)
+
+	assert.Equal(t, ErrNoTenant, _m.Select().Err)
+	_, err := _m.Count()
+	assert.Equal(t, ErrNoTenant, err)
+	_, err = _m.Insert(Record{"title": "x"})
+	assert.Equal(t, ErrNoTenant, err)
+	_, _, err = _m.InsertMany([]Record{{"title": "x"}}, 10)
+	assert.Equal(t, ErrNoTenant, err)
+	_, err = _m.Update(Record{"title": "x"}, WhereEq("uid", 1))
+	assert.Equal(t, ErrNoTenant, err)
+	_, err = _m.Delete(WhereEq("uid", 1))
+	assert.Equal(t, ErrNoTenant, err)
+	_, err = _m.Exec("delete from post")
+	assert.Equal(t, ErrNoTenant, err)
+	assert.Equal(t, ErrNoTenant, _m.FindBy(1).Err)
+	assert.Equal(t, ErrNoTenant, _m.Ctx(context.Background()).Select().Err)
+
+	count, err := _m.Tenant(7).Count(WithoutScope())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func Test_TenantOr(t *testing.T) {
+	_m := tenantModel(t)
+	t7 := _m.Ctx(TenantContext(context.Background(), 7))
+	t8 := _m.Tenant(8)
+
+	// uid = 2 of tenant 8 is not selected by the or branch
+	assert.Equal(t, []string{"a", "b"}, titles(t, t7.Select(WhereEq("uid", 1), WhereOrEq("uid", 2), OrderByAsc("id"))))
+	assert.Equal(t, []string{"b"}, titles(t, t7.Select(WhereOrEq("uid", 2))))
+
+	// the or of raw condition doesn't escape from the tenant condition either
+	assert.Equal(t, []string{"a", "b"}, titles(t, t7.Select(WhereRaw("uid = 1 or uid = 2"), OrderByAsc("id"))))
+	res, err := t7.UpdateWithResult(Record{"title": "pwn"}, WhereRaw("uid = 1 or uid = 2"))
+	require.Equal(t, nil, err)
+	assert.Equal(t, int64(2), res.RowsAffected)
+	assert.Equal(t, []string{"c", "d"}, titles(t, t8.Select(OrderByAsc("id"))))
+
+	res, err = t7.UpdateWithResult(Record{"title": "x"}, WhereEq("uid", 1), WhereOrEq("uid", 2))
+	require.Equal(t, nil, err)
+	assert.Equal(t, int64(2), res.RowsAffected)
+	assert.Equal(t, []string{"c", "d"}, titles(t, t8.Select(OrderByAsc("id"))))
+
+	del, err := t7.DeleteWithResult(WhereEq("uid", 1), WhereOrEq("uid", 2))
+	require.Equal(t, nil, err)
+	assert.Equal(t, int64(2), del.RowsAffected)
+	assert.Equal(t, []string{"c", "d"}, titles(t, t8.Select(OrderByAsc("id"))))
+
+	// the row of tenant 8 can't be updated by primary key of tenant 7
+	res, err = t7.UpdateWithResult(Record{"id": 3, "title": "y"})
+	require.Equal(t, nil, err)
+	assert.Equal(t, int64(0), res.RowsAffected)
+	assert.Equal(t, "c", t8.SelectOne(WhereEq("id", 3)).GetString("title"))
+}
+
+func Test_TenantSub(t *testing.T) {
+	_m := tenantModel(t)
+	t7 := _m.Tenant(7)
+
+	assert.Equal(t, []string{"a", "b"}, titles(t, t7.Select(FromSub("p", Table("post")), OrderByAsc("id"))))
+	count, err := t7.Count(FromSub("p", Table("post")))
+	require.Equal(t, nil, err)
+	assert.Equal(t, int64(2), count)
+
+	rows := t7.Select(WhereEq("uid", 1), UnionAll(Table("post"), WhereEq("uid", 2), WhereOrEq("uid", 1)), OrderByAsc("title"))
+	assert.Equal(t, []string{"a", "a", "b"}, titles(t, rows))
+	count, err = t7.Count(Field("title"), Union(Table("post"), Field("title")))
+	require.Equal(t, nil, err)
+	assert.Equal(t, int64(2), count)
+
+	rows = t7.Select(WithCTE("p", Table("post"), WhereEq("uid", 1)), FromSub("c", Table("p")))
+	assert.Equal(t, []string{"a"}, titles(t, rows))
+	rows = t7.Select(WhereInSub("id", Table("post"), Field("id"), WhereEq("uid", 2)))
+	assert.Equal(t, []string{"b"}, titles(t, rows))
+}
+
+func Test_TenantWrite(t *testing.T) {
+	_m := tenantModel(t)
+
+	id, err := _m.Tenant(7).Insert(Record{"title": "e"})
+	require.Equal(t, nil, err)
+	assert.Equal(t, "7", _m.Tenant(7).SelectOne(WhereEq("id", id)).GetString("tenant_id"))
+	assert.Equal(t, ErrNotFound, _m.Tenant(8).SelectOne(WhereEq("id", id)).Err)
+
+	_, err = _m.Tenant(7).Insert(Record{"title": "f", "tenant_id": 8})
+	assert.NotNil(t, err)
+	_, err = _m.Tenant(7).Update(Record{"tenant_id": 8}, WhereEq("id", id))
+	assert.NotNil(t, err)
+
+	_, err = _m.Tenant(8).Upsert(Record{"title": "g"}, []string{"id"}, []string{"title"})
+	assert.NotNil(t, err)
+	inserted, err := _m.Tenant(8).Upsert(Record{"id": 100, "title": "g"}, []string{"id"}, []string{"title"})
+	require.Equal(t, nil, err)
+	assert.Equal(t, true, inserted)
+	assert.Equal(t, "8", _m.Tenant(8).SelectOne(WhereEq("id", 100)).GetString("tenant_id"))
+}
+
+func Test_TenantRelation(t *testing.T) {
+	_m := tenantModel(t)
+	comment := New("comment", WithConn(sqliteConn(t)))
+	_, err := comment.Insert(Record{"tenant_id": 7, "post_id": 1, "body": "ok"})
+	require.Equal(t, nil, err)
+	// the inconsistent comment of other tenant is not loaded
+	_, err = comment.Insert(Record{"tenant_id": 8, "post_id": 1, "body": "leak"})
+	require.Equal(t, nil, err)
+
+	row := _m.Tenant(7).SelectOne(WhereEq("id", 1))
+	require.Equal(t, nil, row.Err)
+	assert.Equal(t, 1, len(row.Data["body"].([]interface{})))
+}
+
+func Test_TenantSharedRelation(t *testing.T) {
+	tenantModel(t)
+	level := New("level", WithConn(sqliteConn(t)))
+	_, err := level.Exec(`
+CREATE TABLE "level" (
+    id   integer not null primary key autoincrement,
+    name varchar(64) default '' not null
+)`)
+	require.Equal(t, nil, err)
+	_, err = level.Insert(Record{"name": "gold"})
+	require.Equal(t, nil, err)
+
+	// the shared table has no tenant column
+	_m := New("post", WithConn(sqliteConn(t)), WithTenant("tenant_id"),
+		HasOne(HasOpts{Conn: sqliteConn(t), Table: "level", LocalKey: "uid", OtherKeys: []string{"name"}}))
+	assert.NotNil(t, _m.Tenant(7).SelectOne(WhereEq("uid", 1)).Err)
+
+	_m = New("post", WithConn(sqliteConn(t)), WithTenant("tenant_id"),
+		HasOne(HasOpts{Conn: sqliteConn(t), Table: "level", LocalKey: "uid", OtherKeys: []string{"name"}, NoTenant: true}))
+	row := _m.Tenant(7).SelectOne(WhereEq("uid", 1))
+	require.Equal(t, nil, row.Err)
+	assert.Equal(t, "gold", row.GetString("name"))
+}
@@ -1,12 +1,16 @@
 package fly
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var dsn = "root@tcp(127.0.0.1:3306)/fly_test?&parseTime=true"
@@ -147,3 +151,216 @@ func Test_Count(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, int64(0), count)
 }
+
+func Test_Aggregate(t *testing.T) {
+	sum, err := m.Sum("level_id", WhereGe("id", 1))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, sum >= 0)
+
+	max, err := m.Max("id")
+	assert.Equal(t, nil, err)
+	min, err := m.Min("id")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, max >= min)
+}
+
+func Test_Transaction(t *testing.T) {
+	errRollback := errors.New("rollback")
+	err := Transaction("default", func(tx *Tx) error {
+		_m := m.WithTx(tx)
+		_, err := _m.Update(map[string]interface{}{"id": 1, "status": 2})
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "2", _m.SelectOne(WhereEq("id", 1)).GetString("status"))
+		return errRollback
+	})
+	assert.Equal(t, errRollback, err)
+
+	tx, err := Begin("default")
+	require.Equal(t, nil, err)
+	_, err = m.WithTx(tx).Update(map[string]interface{}{"id": 1, "status": 1})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, tx.Commit())
+}
+
+func Test_NestedTransaction(t *testing.T) {
+	committed := 0
+	err := Transaction("default", func(tx *Tx) error {
+		_m := m.WithTx(tx)
+		_, err := _m.Update(map[string]interface{}{"id": 1, "status": 3})
+		if err != nil {
+			return err
+		}
+		tx.AfterCommit(func() { committed++ })
+
+		err = tx.Transaction(func(tx *Tx) error {
+			tx.AfterCommit(func() { committed += 10 })
+			_, err := m.WithTx(tx).Update(map[string]interface{}{"id": 1, "status": 4})
+			if err != nil {
+				return err
+			}
+			return errors.New("inner rollback")
+		})
+		assert.Equal(t, "inner rollback", err.Error())
+		assert.Equal(t, "3", _m.SelectOne(WhereEq("id", 1)).GetString("status"))
+		assert.Equal(t, 0, committed)
+		return nil
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, committed)
+}
+
+func Test_NestedTransactionContext(t *testing.T) {
+	// the service joins the transaction of ctx instead of starting a new one
+	service := func(ctx context.Context) error {
+		return TransactionContext(ctx, "default", func(tx *Tx) error {
+			assert.NotNil(t, tx.parent)
+			_, err := m.WithTx(tx).Update(map[string]interface{}{"id": 1, "status": 4})
+			if err != nil {
+				return err
+			}
+			return errors.New("inner rollback")
+		})
+	}
+
+	err := TransactionContext(context.Background(), "default", func(tx *Tx) error {
+		_m := m.WithTx(tx)
+		_, err := _m.Update(map[string]interface{}{"id": 1, "status": 3})
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "inner rollback", service(tx.Context()).Error())
+		assert.Equal(t, "3", _m.SelectOne(WhereEq("id", 1)).GetString("status"))
+		return nil
+	})
+	assert.Equal(t, nil, err)
+
+	_, err = m.Update(map[string]interface{}{"id": 1, "status": 1})
+	assert.Equal(t, nil, err)
+}
+
+func Test_TransactionModelContext(t *testing.T) {
+	_m := sqliteModel(t, "post", `
+CREATE TABLE "post" (
+    id    integer not null primary key autoincrement,
+    title varchar(64) default '' not null
+)`)
+	// the only connection is held by the transaction, the model of another connection would wait until timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	errRollback := errors.New("rollback")
+	err := TransactionContext(ctx, sqliteConn(t), func(tx *Tx) error {
+		_m := _m.Ctx(tx.Context())
+		_, err := _m.Insert(Record{"title": "a"})
+		assert.Equal(t, nil, err)
+		_, _, err = _m.InsertMany([]Record{{"title": "b"}}, 10)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []string{"a", "b"}, titles(t, _m.Select(OrderByAsc("id"))))
+		return errRollback
+	})
+	assert.Equal(t, errRollback, err)
+
+	count, err := _m.Count()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func Test_Ctx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rows := m.Ctx(ctx).Select(WhereGe("id", 1))
+	assert.Equal(t, true, errors.Is(rows.Err, context.Canceled))
+
+	_m := New("user", WithTimeout(time.Nanosecond, "Select"))
+	rows = _m.Select(WhereGe("id", 1))
+	assert.Equal(t, true, errors.Is(rows.Err, context.DeadlineExceeded))
+}
+
+func Test_Cursor(t *testing.T) {
+	cur := m.Cursor(WhereGe("id", 1))
+	defer cur.Close()
+	count := 0
+	for cur.Next() {
+		var u User
+		err := cur.Row().Binding(&u)
+		assert.Equal(t, nil, err)
+		count++
+	}
+	assert.Equal(t, nil, cur.Err())
+
+	total, err := m.Count(WhereGe("id", 1))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, total, int64(count))
+}
+
+func Test_ChunkById(t *testing.T) {
+	var ids []int64
+	err := m.ChunkById(2, func(rows *Rows) error {
+		var users []User
+		if err := rows.Binding(&users); err != nil {
+			return err
+		}
+		for _, u := range users {
+			ids = append(ids, u.ID)
+		}
+		return nil
+	}, Field("id", "name"))
+	assert.Equal(t, nil, err)
+
+	count, err := m.Count()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, count, int64(len(ids)))
+
+	errStop := errors.New("stop")
+	err = m.Chunk(1, func(rows *Rows) error {
+		return errStop
+	})
+	assert.Equal(t, errStop, err)
+}
+
+func Test_FirstOrCreate(t *testing.T) {
+	row, created, err := m.FirstOrCreate(Record{"name": "Marin"}, Record{"level_id": 1})
+	require.Equal(t, nil, err)
+	assert.Equal(t, true, created)
+	assert.Equal(t, "Marin", row.GetString("name"))
+
+	row, created, err = m.FirstOrCreate(Record{"name": "Marin"}, Record{"level_id": 2})
+	require.Equal(t, nil, err)
+	assert.Equal(t, false, created)
+	assert.Equal(t, "1", row.GetString("level_id"))
+
+	row, created, err = m.UpdateOrCreate(Record{"name": "Marin"}, Record{"level_id": 2})
+	require.Equal(t, nil, err)
+	assert.Equal(t, false, created)
+	assert.Equal(t, "2", row.GetString("level_id"))
+}
+
+func Test_Increment(t *testing.T) {
+	row := m.SelectOne(WhereEq("name", "Seiya"))
+	assert.Equal(t, nil, row.Err)
+	before := cast.ToInt(row.GetString("level_id"))
+
+	_, err := m.Increment("level_id", 2, WhereEq("name", "Seiya"))
+	assert.Equal(t, nil, err)
+	_, err = m.Decrement("level_id", 1, WhereEq("name", "Seiya"))
+	assert.Equal(t, nil, err)
+
+	row = m.SelectOne(WhereEq("name", "Seiya"))
+	assert.Equal(t, before+1, cast.ToInt(row.GetString("level_id")))
+}
+
+func Test_UpdateWithResult(t *testing.T) {
+	res, err := m.UpdateWithResult(Record{"level_id": 9}, WhereEq("name", "not-exists"))
+	require.Equal(t, nil, err)
+	assert.Equal(t, int64(0), res.RowsAffected)
+
+	_m := New("user", WithStrict())
+	_, err = _m.UpdateWithResult(Record{"id": -1, "level_id": 9})
+	assert.Equal(t, ErrNotFound, err)
+
+	del, err := m.DeleteWithResult(WhereEq("name", "not-exists"))
+	require.Equal(t, nil, err)
+	assert.Equal(t, int64(0), del.RowsAffected)
+}
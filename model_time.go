@@ -0,0 +1,60 @@
+package fly
+
+import (
+	"time"
+)
+
+// WithoutTimestamps return a copy of the model which not fill the timestamps of WithTimestamps
+func (m *model) WithoutTimestamps() Model {
+	_m := *m
+	_m.skipTimestamps = true
+	return &_m
+}
+
+// dateTime the format of timestamps in the location of WithLocation
+const dateTime = "2006-01-02 15:04:05"
+
+// now the current time of timestamps, unix seconds for WithUnixTimestamps.
+// it's formatted in the location of WithLocation, otherwise the driver convert the time.Time (e.g. by the loc of mysql dsn)
+func (m *model) now() interface{} {
+	now := time.Now()
+	if m.clock != nil {
+		now = m.clock()
+	}
+	if m.unixTime {
+		return now.Unix()
+	}
+	if m.location != nil {
+		return now.In(m.location).Format(dateTime)
+	}
+	return now
+}
+
+// touch return a copy of record with the columns set to now, the columns the record already has are kept
+func (m *model) touch(record Record, columns ...string) Record {
+	if m.skipTimestamps {
+		return record
+	}
+	var now interface{}
+	var _record Record
+	for _, v := range columns {
+		if v == "" {
+			continue
+		}
+		if _, ok := record[v]; ok {
+			continue
+		}
+		if _record == nil {
+			now = m.now()
+			_record = make(Record, len(record)+len(columns))
+			for k, val := range record {
+				_record[k] = val
+			}
+		}
+		_record[v] = now
+	}
+	if _record == nil {
+		return record
+	}
+	return _record
+}
@@ -0,0 +1,70 @@
+package fly
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const timestampsTable = `
+CREATE TABLE "post" (
+    id         integer not null primary key autoincrement,
+    title      varchar(64) default '' not null unique,
+    created_at %s,
+    updated_at %s
+)`
+
+func Test_Timestamps(t *testing.T) {
+	now := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := func() time.Time { return now }
+	_m := sqliteModel(t, "post", fmt.Sprintf(timestampsTable, "varchar(32)", "varchar(32)"),
+		WithTimestamps("created_at", "updated_at"), WithClock(clock), WithLocation(time.FixedZone("CST", 8*3600)))
+
+	id, err := _m.Insert(Record{"title": "a"})
+	require.Equal(t, nil, err)
+	row := _m.SelectOne(WhereEq("id", id))
+	assert.Equal(t, "2022-01-02 11:04:05", row.GetString("created_at"))
+	assert.Equal(t, "2022-01-02 11:04:05", row.GetString("updated_at"))
+
+	// the value of record is kept
+	_, _, err = _m.InsertMany([]Record{{"title": "b", "created_at": "2000-01-01 00:00:00"}}, 10)
+	require.Equal(t, nil, err)
+	row = _m.SelectOne(WhereEq("title", "b"))
+	assert.Equal(t, "2000-01-01 00:00:00", row.GetString("created_at"))
+	assert.Equal(t, "2022-01-02 11:04:05", row.GetString("updated_at"))
+
+	now = now.Add(time.Hour)
+	_, err = _m.Update(Record{"id": id, "title": "a1"})
+	require.Equal(t, nil, err)
+	row = _m.SelectOne(WhereEq("id", id))
+	assert.Equal(t, "2022-01-02 11:04:05", row.GetString("created_at"))
+	assert.Equal(t, "2022-01-02 12:04:05", row.GetString("updated_at"))
+
+	now = now.Add(time.Hour)
+	inserted, err := _m.Upsert(Record{"title": "a1"}, []string{"title"}, []string{"title"})
+	require.Equal(t, nil, err)
+	assert.Equal(t, false, inserted)
+	row = _m.SelectOne(WhereEq("id", id))
+	assert.Equal(t, "2022-01-02 11:04:05", row.GetString("created_at"))
+	assert.Equal(t, "2022-01-02 13:04:05", row.GetString("updated_at"))
+
+	now = now.Add(time.Hour)
+	_, err = _m.WithoutTimestamps().Update(Record{"id": id, "title": "a2"})
+	require.Equal(t, nil, err)
+	assert.Equal(t, "2022-01-02 13:04:05", _m.SelectOne(WhereEq("id", id)).GetString("updated_at"))
+}
+
+func Test_UnixTimestamps(t *testing.T) {
+	now := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	_m := sqliteModel(t, "post", fmt.Sprintf(timestampsTable, "integer default 0 not null", "integer default 0 not null"),
+		WithTimestamps("created_at", ""), WithUnixTimestamps(), WithClock(func() time.Time { return now }))
+
+	id, err := _m.Insert(Record{"title": "a"})
+	require.Equal(t, nil, err)
+	row := _m.SelectOne(WhereEq("id", id))
+	assert.Equal(t, "1641092645", row.GetString("created_at"))
+	assert.Equal(t, "0", row.GetString("updated_at"))
+}
@@ -0,0 +1,83 @@
+package fly
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// aliveCond the condition of the rows not soft deleted
+func (m *model) aliveCond(key string) Option {
+	if m.deletedAt {
+		return WhereNull(key)
+	}
+	return WhereEq(key, 0)
+}
+
+func (m *model) trashedCond(key string) Option {
+	if m.deletedAt {
+		return WhereNotNull(key)
+	}
+	return WhereNotEq(key, 0)
+}
+
+func (m *model) deletedRecord() Record {
+	if m.deletedAt {
+		return Record{m.fakeDelKey: m.now()}
+	}
+	return Record{m.fakeDelKey: 1}
+}
+
+// Restore restore the soft deleted rows matched opt
+func (m *model) Restore(opt ...Option) (ok bool, err error) {
+	if len(opt) == 0 {
+		return false, errors.New("danger, restore query must with some condition")
+	}
+	if m.err != nil {
+		return false, m.err
+	}
+	if m.fakeDelKey == "" {
+		return false, errors.New("restore must with soft delete key (WithFakeDelKey, WithDeletedAt)")
+	}
+
+	ctx, cancel := m.context("Restore")
+	defer cancel()
+
+	record := Record{m.fakeDelKey: 0}
+	if m.deletedAt {
+		record = Record{m.fakeDelKey: nil}
+	}
+	_m := m.withCtx(ctx)
+	_m.enableValidator = false
+	res, err := _m.UpdateWithResult(record, append(groupOr(opt), m.trashedCond(m.fakeDelKey))...)
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// ForceDelete delete the rows matched opt physically, include the soft deleted ones
+func (m *model) ForceDelete(opt ...Option) (ok bool, err error) {
+	_m := *m
+	_m.fakeDelKey = ""
+	return _m.Delete(opt...)
+}
+
+// forgetCache delete the FindBy cache of the rows matched opt, which are going to be changed
+func (m *model) forgetCache(ctx context.Context, opt []Option) error {
+	if cache == nil {
+		return nil
+	}
+	_sql, args := SelectBuilder(append(opt, table(m.table), database(m.database), dialect(m.dialect), Field(m.primaryKey))...)
+	rows, err := query(ctx, m.writer(), _sql, args...)
+	if err != nil {
+		return err
+	}
+	for _, v := range rows {
+		if err := cacheDel(ctx, m.cacheKeyPrefix(cast.ToInt64(v.Data[m.primaryKey]))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
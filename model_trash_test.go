@@ -0,0 +1,89 @@
+package fly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func trashModel(t *testing.T) Model {
+	_m := sqliteModel(t, "post", `
+CREATE TABLE "post" (
+    id         integer not null primary key autoincrement,
+    uid        integer default 0 not null,
+    title      varchar(64) default '' not null,
+    deleted_at datetime
+)`, WithDeletedAt("deleted_at"))
+	for _, v := range []Record{{"uid": 1, "title": "a"}, {"uid": 1, "title": "b"}, {"uid": 2, "title": "c"}} {
+		_, err := _m.Insert(v)
+		require.Equal(t, nil, err)
+	}
+	return _m
+}
+
+func titles(t *testing.T, rows *Rows) (list []string) {
+	require.Equal(t, nil, rows.Err)
+	for _, v := range rows.List {
+		list = append(list, v.GetString("title"))
+	}
+	return list
+}
+
+func Test_SoftDeleteOr(t *testing.T) {
+	_m := trashModel(t)
+
+	ok, err := _m.Delete(WhereEq("title", "a"))
+	require.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+
+	// the trashed row a is not selected by the or branch
+	assert.Equal(t, []string{"b", "c"}, titles(t, _m.Select(WhereEq("uid", 1), WhereOrEq("uid", 2), OrderByAsc("id"))))
+	count, err := _m.Count(WhereEq("uid", 1), WhereOrEq("uid", 2))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, []string{"a"}, titles(t, _m.Select(WhereEq("uid", 1), WhereOrEq("uid", 2), OnlyTrashed())))
+	assert.Equal(t, 3, len(titles(t, _m.Select(WhereEq("uid", 1), WhereOrEq("uid", 2), WithTrashed()))))
+	assert.Equal(t, []string{"b", "c"}, titles(t, _m.Select(WhereRaw("uid = 1 or uid = 2"), OrderByAsc("id"))))
+
+	// the deleted_at of trashed row a is not overwritten
+	deletedAt := _m.SelectOne(WhereEq("title", "a"), OnlyTrashed()).Data["deleted_at"]
+	time.Sleep(time.Second)
+	res, err := _m.DeleteWithResult(WhereEq("title", "a"), WhereOrEq("title", "b"))
+	require.Equal(t, nil, err)
+	assert.Equal(t, int64(1), res.RowsAffected)
+	assert.Equal(t, deletedAt, _m.SelectOne(WhereEq("title", "a"), OnlyTrashed()).Data["deleted_at"])
+
+	// only the trashed rows are restored
+	ok, err = _m.Restore(WhereEq("title", "a"), WhereOrEq("title", "c"))
+	require.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, []string{"a", "c"}, titles(t, _m.Select(OrderByAsc("id"))))
+	assert.Equal(t, []string{"b"}, titles(t, _m.Select(OnlyTrashed())))
+
+	ok, err = _m.ForceDelete(WhereEq("title", "b"), WhereOrEq("title", "c"))
+	require.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, []string{"a"}, titles(t, _m.Select(WithTrashed())))
+}
+
+func Test_Restore(t *testing.T) {
+	id, err := m.Insert(Record{"name": "Aiolia", "level_id": 1})
+	assert.Equal(t, nil, err)
+
+	_, err = m.Delete(WhereEq("id", id))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ErrNotFound, m.SelectOne(WhereEq("id", id)).Err)
+	assert.Equal(t, nil, m.SelectOne(WhereEq("id", id), OnlyTrashed()).Err)
+
+	ok, err := m.Restore(WhereEq("id", id))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, nil, m.SelectOne(WhereEq("id", id)).Err)
+
+	ok, err = m.ForceDelete(WhereEq("id", id))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, ErrNotFound, m.SelectOne(WhereEq("id", id), WithTrashed()).Err)
+}
@@ -0,0 +1,227 @@
+package fly
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// Upsert insert the record, or update the update columns of the row conflict on the conflict columns
+// (unique index or primary key), empty update keep the conflict row as it is.
+// inserted is false when the row is updated.
+func (m *model) Upsert(record Record, conflict []string, update []string) (inserted bool, err error) {
+	res, err := m.upsert("Upsert", []Record{record}, conflict, update, 1)
+	if err != nil {
+		return false, err
+	}
+	return res[0], nil
+}
+
+// UpsertMany upsert records by multi rows insert statements in one transaction, batchSize is the same as InsertMany.
+// inserted[i] reports whether records[i] is inserted, a record conflict with the previous one in records is updated.
+func (m *model) UpsertMany(records []Record, conflict []string, update []string, batchSize int) (inserted []bool, err error) {
+	return m.upsert("UpsertMany", records, conflict, update, batchSize)
+}
+
+// upsert the existing rows are selected in the transaction before writing to tell inserted from updated,
+// it may be inaccurate when the same rows are inserted concurrently by others
+func (m *model) upsert(op string, records []Record, conflict []string, update []string, batchSize int) (inserted []bool, err error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty records to upsert")
+	}
+	if len(conflict) == 0 {
+		return nil, errors.New("upsert must with conflict columns")
+	}
+
+	var kv []interface{}
+	defer dbLog(op, time.Now(), &err, &kv)
+
+	ctx, cancel := m.context(op)
+	defer cancel()
+
+	if len(update) > 0 && m.updatedAt != "" && !m.skipTimestamps {
+		_update := append([]string{}, update...)
+		for _, v := range update {
+			if v == m.updatedAt {
+				_update = nil
+			}
+		}
+		if _update != nil {
+			update = append(_update, m.updatedAt)
+		}
+	}
+
+	var _records []Record
+	for _, v := range records {
+		if len(v) == 0 {
+			return nil, errors.New("empty record to upsert")
+		}
+		if err := m.checkColumns(ctx, nil, append(append(sortedKeys(v), conflict...), update...)...); err != nil {
+			return nil, err
+		}
+		_record, err := m.tenantRecord(v, true)
+		if err != nil {
+			return nil, err
+		}
+		_record, err = m.hookInput(ctx, m.touch(_record, m.createdAt, m.updatedAt))
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range conflict {
+			if _, ok := _record[c]; !ok {
+				return nil, errors.New("upsert record must contain the conflict column " + c)
+			}
+		}
+		_records = append(_records, _record)
+	}
+
+	err = m.transaction(ctx, func(_m *model) error {
+		var err error
+		inserted, err = _m.upsertRecords(ctx, _records, conflict, update, batchSize, &kv)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inserted, nil
+}
+
+func (m *model) upsertRecords(ctx context.Context, records []Record, conflict []string, update []string, batchSize int, kv *[]interface{}) ([]bool, error) {
+	existing, err := m.conflictRows(ctx, records, conflict)
+	if err != nil {
+		return nil, err
+	}
+
+	keepPk := false
+	for _, v := range conflict {
+		if v == m.primaryKey {
+			keepPk = true
+		}
+	}
+
+	inserted := make([]bool, len(records))
+	seen := map[string]bool{}
+	for i, v := range records {
+		key := conflictKey(v, conflict)
+		id, ok := existing[key]
+		inserted[i] = !ok && !seen[key]
+		seen[key] = true
+
+		// the validators (e.g. Unique) exclude the conflict row itself
+		if ok && !keepPk {
+			v[m.primaryKey] = id
+		}
+		if err := m.valid(ctx, v); err != nil {
+			return nil, err
+		}
+		if !keepPk {
+			delete(v, m.primaryKey)
+		}
+		if len(v) == 0 {
+			return nil, errors.New("empty record to upsert")
+		}
+	}
+
+	for _, batch := range m.insertBatches(records, batchSize) {
+		for _, _batch := range splitConflict(batch, conflict) {
+			ks := sortedKeys(_batch[0])
+			var rows [][]interface{}
+			for _, v := range _batch {
+				var row []interface{}
+				for _, k := range ks {
+					row = append(row, v[k])
+				}
+				rows = append(rows, row)
+			}
+
+			_sql, args := InsertBuilder(dialect(m.dialect), table(m.table), Field(ks...), Values(rows...), conflictUpdate(conflict, update))
+			*kv = append(*kv, "sql:", _sql)
+
+			if _, err := exec(ctx, m.writer(), _sql, args...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return inserted, nil
+}
+
+// conflictRows the primary keys of existing rows conflict with records, keyed by conflictKey
+func (m *model) conflictRows(ctx context.Context, records []Record, conflict []string) (map[string]interface{}, error) {
+	existing := map[string]interface{}{}
+	size := m.dialect.MaxPlaceholders() / len(conflict)
+	for start := 0; start < len(records); start += size {
+		end := start + size
+		if end > len(records) {
+			end = len(records)
+		}
+
+		var where Option
+		if len(conflict) == 1 {
+			var values []interface{}
+			for _, v := range records[start:end] {
+				values = append(values, v[conflict[0]])
+			}
+			where = WhereIn(conflict[0], values)
+		} else {
+			var groups []Option
+			for i, v := range records[start:end] {
+				var cond []Option
+				for _, c := range conflict {
+					cond = append(cond, WhereEq(c, v[c]))
+				}
+				if i == 0 {
+					groups = append(groups, WhereGroup(cond...))
+				} else {
+					groups = append(groups, WhereOrGroup(cond...))
+				}
+			}
+			where = WhereGroup(groups...)
+		}
+
+		opt := []Option{dialect(m.dialect), table(m.table), Field(append([]string{m.primaryKey}, conflict...)...), where}
+		if m.tenantKey != "" {
+			opt = append(opt, m.tenantCond(m.tenantKey))
+		}
+		_sql, args := SelectBuilder(opt...)
+		rows, err := query(ctx, m.writer(), _sql, args...)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			existing[conflictKey(row.Data, conflict)] = row.Data[m.primaryKey]
+		}
+	}
+	return existing, nil
+}
+
+func conflictKey(record map[string]interface{}, conflict []string) string {
+	var values []string
+	for _, v := range conflict {
+		values = append(values, cast.ToString(record[v]))
+	}
+	return strings.Join(values, "\x00")
+}
+
+// splitConflict split batch to make sure one statement not touch the same row twice, which postgres not allowed
+func splitConflict(batch []Record, conflict []string) (batches [][]Record) {
+	var _batch []Record
+	keys := map[string]bool{}
+	for _, v := range batch {
+		key := conflictKey(v, conflict)
+		if keys[key] {
+			batches = append(batches, _batch)
+			_batch = nil
+			keys = map[string]bool{}
+		}
+		keys[key] = true
+		_batch = append(_batch, v)
+	}
+	return append(batches, _batch)
+}
@@ -0,0 +1,36 @@
+package fly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_splitConflict(t *testing.T) {
+	batch := []Record{
+		{"name": "a", "age": 1},
+		{"name": "b", "age": 2},
+		{"name": "a", "age": 3},
+		{"name": "c", "age": 4},
+	}
+	assert.Equal(t, [][]Record{batch[0:2], batch[2:4]}, splitConflict(batch, []string{"name"}))
+	assert.Equal(t, [][]Record{batch}, splitConflict(batch, []string{"name", "age"}))
+}
+
+func Test_Upsert(t *testing.T) {
+	inserted, err := m.Upsert(Record{"name": "Shun", "level_id": 1}, []string{"name"}, []string{"level_id"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, inserted)
+
+	inserted, err = m.Upsert(Record{"name": "Shun", "level_id": 2}, []string{"name"}, []string{"level_id"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, inserted)
+
+	res, err := m.UpsertMany([]Record{
+		{"name": "Shun", "level_id": 3},
+		{"name": "Ikki", "level_id": 3},
+		{"name": "Ikki", "level_id": 4},
+	}, []string{"name"}, []string{"level_id"}, 100)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []bool{false, true, false}, res)
+}
@@ -0,0 +1,64 @@
+package fly
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// ErrStaleRecord the row is updated by others after it was read, see WithVersionColumn
+var ErrStaleRecord = errors.New("stale record, the version is changed")
+
+// versionUpdate return a copy of record with version = version + 1, and the condition of the version it read.
+// the version is checked only if the record has primary key, which must have the version too
+func (m *model) versionUpdate(record Record, byPk bool) (_record Record, cond Option, version int64, err error) {
+	old, ok := record[m.version]
+	if _, isExpr := old.(Expression); isExpr {
+		return record, nil, 0, nil
+	}
+	if byPk {
+		if !ok {
+			return nil, nil, 0, errors.New("update by primary key must with the version column " + m.version)
+		}
+		version, err = cast.ToInt64E(old)
+		if err != nil {
+			return nil, nil, 0, errors.Wrap(err, "invalid version")
+		}
+		cond = WhereEq(m.version, old)
+		version++
+	}
+
+	_record = make(Record, len(record))
+	for k, v := range record {
+		_record[k] = v
+	}
+	_record[m.version] = Expr(quote(m.version) + " + 1")
+	return _record, cond, version, nil
+}
+
+// versionField append the version column to the selected fields with primary key,
+// so the rows can be updated with the version they read
+func (m *model) versionField(opt []Option) []Option {
+	if m.version == "" {
+		return opt
+	}
+	opts := new(Options)
+	for _, o := range opt {
+		o(opts)
+	}
+	if len(opts.groupBy) > 0 || len(opts.union) > 0 || opts.from != nil {
+		return opt
+	}
+	hasPk := false
+	for _, v := range opts.columns {
+		switch columnName(v) {
+		case m.primaryKey:
+			hasPk = true
+		case m.version, "*":
+			return opt
+		}
+	}
+	if !hasPk {
+		return opt
+	}
+	return append(opt, FieldRaw(quote(m.qualify(opts, m.version))))
+}
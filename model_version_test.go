@@ -0,0 +1,80 @@
+package fly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func versionModel(t *testing.T) Model {
+	_m := sqliteModel(t, "post", `
+CREATE TABLE "post" (
+    id      integer not null primary key autoincrement,
+    uid     integer default 0 not null,
+    title   varchar(64) default '' not null,
+    version integer default 0 not null
+)`, WithVersionColumn("version"))
+	for _, v := range []Record{{"uid": 1, "title": "a"}, {"uid": 2, "title": "b"}, {"uid": 3, "title": "c"}} {
+		_, err := _m.Insert(v)
+		require.Equal(t, nil, err)
+	}
+	return _m
+}
+
+func Test_VersionColumn(t *testing.T) {
+	_m := versionModel(t)
+
+	// the version is selected with the primary key
+	row := _m.SelectOne(Field("id", "title"), WhereEq("title", "a"))
+	require.Equal(t, nil, row.Err)
+	assert.Equal(t, "0", row.GetString("version"))
+
+	stale := Record{"id": row.Data["id"], "title": "a2", "version": row.Data["version"]}
+	res, err := _m.UpdateWithResult(Record{"id": row.Data["id"], "title": "a1", "version": row.Data["version"]})
+	require.Equal(t, nil, err)
+	assert.Equal(t, int64(1), res.Version)
+
+	_, err = _m.Update(stale)
+	assert.Equal(t, ErrStaleRecord, err)
+	_, err = _m.Update(Record{"id": 100, "title": "x", "version": 0})
+	assert.Equal(t, ErrNotFound, err)
+	_, err = _m.Update(Record{"id": row.Data["id"], "title": "a2"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "a1", _m.SelectOne(WhereEq("id", row.Data["id"])).GetString("title"))
+
+	// the or conditions don't bypass the version check
+	_, err = _m.Update(stale, WhereEq("uid", 1), WhereOrEq("uid", 2))
+	assert.Equal(t, ErrStaleRecord, err)
+	assert.Equal(t, "b", _m.SelectOne(WhereEq("uid", 2)).GetString("title"))
+
+	// the update without primary key increase the version too
+	ok, err := _m.Update(Record{"title": "x"}, WhereEq("uid", 2), WhereOrEq("uid", 3))
+	require.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "1", _m.SelectOne(WhereEq("uid", 3)).GetString("version"))
+}
+
+func Test_VersionColumnUpdateBy(t *testing.T) {
+	_m := versionModel(t)
+
+	row := _m.FindBy(1)
+	require.Equal(t, nil, row.Err)
+	data := Record{"title": "a1", "version": row.Data["version"]}
+	_, err := _m.UpdateBy(1, data)
+	require.Equal(t, nil, err)
+
+	// the stale version of UpdateBy is checked
+	_, err = _m.UpdateBy(1, data)
+	assert.Equal(t, ErrStaleRecord, err)
+
+	// the cache of FindBy is forgotten by Update, so the version can be round-tripped
+	row = _m.FindBy(1)
+	require.Equal(t, nil, row.Err)
+	assert.Equal(t, "1", row.GetString("version"))
+	_, err = _m.Update(Record{"id": 1, "title": "a2", "version": row.Data["version"]})
+	require.Equal(t, nil, err)
+	row = _m.FindBy(1)
+	assert.Equal(t, "a2", row.GetString("title"))
+	assert.Equal(t, "2", row.GetString("version"))
+}
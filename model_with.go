@@ -2,6 +2,7 @@ package fly
 
 import (
 	"database/sql"
+	"time"
 )
 
 type With = func(*model)
@@ -12,6 +13,109 @@ func WithDB(db *sql.DB) With {
 	}
 }
 
+// WithDialect set the sql dialect, by default it's selected by the Config.Driver of the connection
+func WithDialect(d Dialect) With {
+	return func(b *model) {
+		b.dialect = d
+	}
+}
+
+// WithTimeout set the default timeout of the operations (Select, Count, Insert, Update, Delete ...),
+// for all operations if op is empty
+func WithTimeout(d time.Duration, op ...string) With {
+	return func(b *model) {
+		if b.timeout == nil {
+			b.timeout = make(map[string]time.Duration)
+		}
+		if len(op) == 0 {
+			b.timeout[""] = d
+		}
+		for _, v := range op {
+			b.timeout[v] = d
+		}
+	}
+}
+
+// WithStrict the Update (UpdateWithResult) of a record with primary key return ErrNotFound if the row not exists
+func WithStrict() With {
+	return func(b *model) {
+		b.strict = true
+	}
+}
+
+// WithTenant the rows of model are isolated by the tenant column, the tenant id is taken from the ctx of model
+// (TenantContext or m.Tenant(id)), it's added to the conditions of query, update, delete and the record of insert,
+// the model without tenant id returns ErrNoTenant
+func WithTenant(column string) With {
+	return func(b *model) {
+		b.tenantKey = column
+	}
+}
+
+// WithGlobalScope the options applied to every Select, Count, Update and Delete of the model,
+// it can be disabled by WithoutScope(name) of a query
+func WithGlobalScope(name string, opt ...Option) With {
+	return func(b *model) {
+		for i, v := range b.globalScopes {
+			if v.name == name {
+				b.globalScopes[i].opt = opt
+				return
+			}
+		}
+		b.globalScopes = append(b.globalScopes, scope{name: name, opt: opt})
+	}
+}
+
+// WithScope define a named local scope, which is applied by m.Scope(name)
+func WithScope(name string, opt ...Option) With {
+	return func(b *model) {
+		if b.scopes == nil {
+			b.scopes = map[string][]Option{}
+		}
+		b.scopes[name] = opt
+	}
+}
+
+// WithVersionColumn optimistic locking, Update increase the version column,
+// and the Update of a record with primary key must have the version it read, ErrStaleRecord is returned if the version is changed
+func WithVersionColumn(name string) With {
+	return func(b *model) {
+		b.version = name
+	}
+}
+
+// WithTimestamps fill the created and updated column by Insert, InsertMany, Upsert and Update,
+// the column is skipped if it's empty or the record has its value
+func WithTimestamps(created, updated string) With {
+	return func(b *model) {
+		b.createdAt = created
+		b.updatedAt = updated
+	}
+}
+
+// WithUnixTimestamps the timestamps (WithTimestamps, WithDeletedAt) are stored as unix seconds instead of time
+func WithUnixTimestamps() With {
+	return func(b *model) {
+		b.unixTime = true
+	}
+}
+
+// WithClock the current time of timestamps, time.Now by default
+func WithClock(now func() time.Time) With {
+	return func(b *model) {
+		b.clock = now
+	}
+}
+
+// WithLocation the timestamps are stored as "2006-01-02 15:04:05" in loc,
+// by default they are time.Time converted by the driver (e.g. the loc of mysql dsn).
+// it has no effect on WithUnixTimestamps
+func WithLocation(loc *time.Location) With {
+	return func(b *model) {
+		b.location = loc
+	}
+}
+
 func WithConn(name string) With {
 	return func(b *model) {
 		b.connection = name
@@ -24,6 +128,14 @@ func WithFakeDelKey(name string) With {
 	}
 }
 
+// WithDeletedAt soft delete by the timestamp column, Delete set it to the current time and the row is deleted when it's not null
+func WithDeletedAt(name string) With {
+	return func(b *model) {
+		b.fakeDelKey = name
+		b.deletedAt = true
+	}
+}
+
 func WithPrimaryKey(name string) With {
 	return func(b *model) {
 		b.primaryKey = name
@@ -0,0 +1,274 @@
+// Package queue a database backed job queue built on fly.Model,
+// jobs are claimed by `select ... for update skip locked`, so multiple workers can share one table.
+//
+// the jobs table (mysql):
+//
+//	CREATE TABLE `fly_jobs` (
+//	  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+//	  `queue` varchar(64) NOT NULL,
+//	  `payload` text NOT NULL,
+//	  `attempts` int NOT NULL DEFAULT 0,
+//	  `available_at` bigint NOT NULL DEFAULT 0,
+//	  `reserved_at` bigint NOT NULL DEFAULT 0,
+//	  `token` varchar(32) NOT NULL DEFAULT '',
+//	  `failed_at` bigint NOT NULL DEFAULT 0,
+//	  `error` text,
+//	  `created_at` bigint NOT NULL DEFAULT 0,
+//	  PRIMARY KEY (`id`),
+//	  KEY `idx_queue_available` (`queue`, `failed_at`, `available_at`)
+//	);
+//
+// the times are unix milliseconds.
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+
+	"github.com/daodao97/fly"
+)
+
+// ErrJobLost the job is not reserved by the caller any more, e.g. its visibility timeout expired and reserved by others
+var ErrJobLost = errors.New("queue: job is not reserved by the caller")
+
+type Job struct {
+	Id       int64
+	Queue    string
+	Payload  string
+	Attempts int
+	token    string
+}
+
+// Backoff the delay before the next retry of a failed job
+type Backoff = func(attempts int) time.Duration
+
+type Queue struct {
+	name         string
+	conn         string
+	table        string
+	maxAttempts  int
+	backoff      Backoff
+	visibility   time.Duration
+	pollInterval time.Duration
+	m            fly.Model
+}
+
+type With = func(*Queue)
+
+func WithConn(conn string) With {
+	return func(q *Queue) {
+		q.conn = conn
+	}
+}
+
+func WithTable(table string) With {
+	return func(q *Queue) {
+		q.table = table
+	}
+}
+
+// WithMaxAttempts the job is marked failed after attempts, default 3
+func WithMaxAttempts(n int) With {
+	return func(q *Queue) {
+		q.maxAttempts = n
+	}
+}
+
+// WithBackoff default 1s, 2s, 4s ... at most 1h
+func WithBackoff(backoff Backoff) With {
+	return func(q *Queue) {
+		q.backoff = backoff
+	}
+}
+
+// WithVisibilityTimeout the reserved job is available to others again if it's not acked or nacked in d, default 30s
+func WithVisibilityTimeout(d time.Duration) With {
+	return func(q *Queue) {
+		q.visibility = d
+	}
+}
+
+// WithPollInterval the interval of Run to reserve jobs when the queue is empty, default 1s
+func WithPollInterval(d time.Duration) With {
+	return func(q *Queue) {
+		q.pollInterval = d
+	}
+}
+
+func exponential(attempts int) time.Duration {
+	if attempts > 12 {
+		return time.Hour
+	}
+	d := time.Second << uint(attempts-1)
+	if d > time.Hour {
+		return time.Hour
+	}
+	return d
+}
+
+// New the queue of name, jobs of all queues can be stored in one table
+func New(name string, opt ...With) *Queue {
+	q := &Queue{
+		name:         name,
+		conn:         "default",
+		table:        "fly_jobs",
+		maxAttempts:  3,
+		backoff:      exponential,
+		visibility:   30 * time.Second,
+		pollInterval: time.Second,
+	}
+	for _, o := range opt {
+		o(q)
+	}
+	q.m = fly.New(q.table, fly.WithConn(q.conn))
+	return q
+}
+
+func now() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+func millis(d time.Duration) int64 {
+	return int64(d / time.Millisecond)
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Enqueue push a job available after delay
+func (q *Queue) Enqueue(ctx context.Context, payload string, delay time.Duration) (id int64, err error) {
+	t := now()
+	return q.m.Ctx(ctx).Insert(fly.Record{
+		"queue":        q.name,
+		"payload":      payload,
+		"attempts":     0,
+		"available_at": t + millis(delay),
+		"created_at":   t,
+	})
+}
+
+// Reserve claim at most n available jobs, they must be acked or nacked in the visibility timeout,
+// the job not acked or nacked in its last attempt is marked failed instead of reserved again.
+// the candidates are selected with skip locked, and claimed by a conditional update,
+// so it's safe on the databases without skip locked (sqlite) too.
+func (q *Queue) Reserve(ctx context.Context, n int) (jobs []*Job, err error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = fly.TransactionContext(ctx, q.conn, func(tx *fly.Tx) error {
+		m := q.m.WithTx(tx).Ctx(ctx)
+		t := now()
+
+		// the worker of these jobs died without ack or nack (e.g. crashed by the job) in the last attempt
+		_, err := m.Update(fly.Record{
+			"token":       "",
+			"reserved_at": 0,
+			"failed_at":   t,
+			"error":       "queue: visibility timeout expired after max attempts",
+		}, fly.WhereEq("queue", q.name), fly.WhereEq("failed_at", 0), fly.WhereLe("available_at", t), fly.WhereGe("attempts", q.maxAttempts))
+		if err != nil {
+			return err
+		}
+
+		rows := m.Select(
+			fly.Field("id"),
+			fly.WhereEq("queue", q.name),
+			fly.WhereEq("failed_at", 0),
+			fly.WhereLe("available_at", t),
+			fly.WhereLt("attempts", q.maxAttempts),
+			fly.OrderByAsc("id"),
+			fly.Limit(n),
+			fly.SkipLocked(),
+		)
+		if rows.Err != nil {
+			return rows.Err
+		}
+		if len(rows.List) == 0 {
+			return nil
+		}
+		var ids []interface{}
+		for _, v := range rows.List {
+			ids = append(ids, cast.ToInt64(v.Data["id"]))
+		}
+
+		// the available_at condition drop the jobs claimed by others after selected
+		_, err = m.Update(fly.Record{
+			"token":        token,
+			"attempts":     fly.Expr("`attempts` + 1"),
+			"reserved_at":  t,
+			"available_at": t + millis(q.visibility),
+		}, fly.WhereIn("id", ids), fly.WhereLe("available_at", t))
+		if err != nil {
+			return err
+		}
+
+		rows = m.Select(fly.WhereEq("token", token), fly.OrderByAsc("id"))
+		if rows.Err != nil {
+			return rows.Err
+		}
+		for _, v := range rows.List {
+			jobs = append(jobs, &Job{
+				Id:       cast.ToInt64(v.Data["id"]),
+				Queue:    cast.ToString(v.Data["queue"]),
+				Payload:  cast.ToString(v.Data["payload"]),
+				Attempts: cast.ToInt(v.Data["attempts"]),
+				token:    token,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Ack the job is done and removed
+func (q *Queue) Ack(ctx context.Context, job *Job) error {
+	res, err := q.m.Ctx(ctx).DeleteWithResult(fly.WhereEq("id", job.Id), fly.WhereEq("token", job.token))
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected == 0 {
+		return ErrJobLost
+	}
+	return nil
+}
+
+// Nack the job failed by cause, it's retried after backoff or marked failed when the attempts reach max attempts
+func (q *Queue) Nack(ctx context.Context, job *Job, cause error) error {
+	record := fly.Record{"token": "", "reserved_at": 0}
+	if cause != nil {
+		record["error"] = cause.Error()
+	}
+	t := now()
+	if job.Attempts >= q.maxAttempts {
+		record["failed_at"] = t
+	} else {
+		record["available_at"] = t + millis(q.backoff(job.Attempts))
+	}
+
+	res, err := q.m.Ctx(ctx).UpdateWithResult(record, fly.WhereEq("id", job.Id), fly.WhereEq("token", job.token))
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected == 0 {
+		return ErrJobLost
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package queue
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daodao97/fly"
+)
+
+var dsn = "./queue_sqlite.test"
+
+func init() {
+	_ = os.Remove(dsn)
+
+	err := fly.Init(map[string]*fly.Config{
+		"queue": {DSN: dsn, Driver: "sqlite3", MaxOpenConn: 1},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = fly.New("fly_jobs", fly.WithConn("queue")).Exec(`
+CREATE TABLE "fly_jobs" (
+    id           integer not null primary key autoincrement,
+    queue        varchar(64) not null,
+    payload      text not null,
+    attempts     integer default 0 not null,
+    available_at integer default 0 not null,
+    reserved_at  integer default 0 not null,
+    token        varchar(32) default '' not null,
+    failed_at    integer default 0 not null,
+    error        text,
+    created_at   integer default 0 not null
+);
+`)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func noBackoff(attempts int) time.Duration {
+	return 0
+}
+
+func Test_Queue(t *testing.T) {
+	ctx := context.Background()
+	q := New("mail", WithConn("queue"), WithMaxAttempts(2), WithBackoff(noBackoff))
+
+	id, err := q.Enqueue(ctx, "hello", 0)
+	assert.Equal(t, nil, err)
+	_, err = q.Enqueue(ctx, "later", time.Hour)
+	assert.Equal(t, nil, err)
+
+	jobs, err := q.Reserve(ctx, 10)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(jobs))
+	assert.Equal(t, id, jobs[0].Id)
+	assert.Equal(t, "hello", jobs[0].Payload)
+	assert.Equal(t, 1, jobs[0].Attempts)
+
+	// reserved job is invisible to others
+	others, err := q.Reserve(ctx, 10)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(others))
+
+	// retry
+	assert.Equal(t, nil, q.Nack(ctx, jobs[0], errors.New("smtp timeout")))
+	jobs, err = q.Reserve(ctx, 10)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(jobs))
+	assert.Equal(t, 2, jobs[0].Attempts)
+
+	// failed after max attempts
+	assert.Equal(t, nil, q.Nack(ctx, jobs[0], errors.New("smtp timeout")))
+	jobs, err = q.Reserve(ctx, 10)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(jobs))
+}
+
+func Test_VisibilityTimeout(t *testing.T) {
+	ctx := context.Background()
+	q := New("sms", WithConn("queue"), WithVisibilityTimeout(50*time.Millisecond))
+
+	_, err := q.Enqueue(ctx, "hi", 0)
+	assert.Equal(t, nil, err)
+
+	jobs, err := q.Reserve(ctx, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(jobs))
+
+	time.Sleep(100 * time.Millisecond)
+	again, err := q.Reserve(ctx, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(again))
+	assert.Equal(t, jobs[0].Id, again[0].Id)
+
+	assert.Equal(t, ErrJobLost, q.Ack(ctx, jobs[0]))
+	assert.Equal(t, nil, q.Ack(ctx, again[0]))
+}
+
+func Test_VisibilityTimeoutMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	q := New("poison", WithConn("queue"), WithMaxAttempts(2), WithVisibilityTimeout(50*time.Millisecond))
+
+	id, err := q.Enqueue(ctx, "crash", 0)
+	assert.Equal(t, nil, err)
+
+	// the worker crashed in every attempt
+	for i := 1; i <= 2; i++ {
+		jobs, err := q.Reserve(ctx, 1)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 1, len(jobs))
+		assert.Equal(t, i, jobs[0].Attempts)
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	jobs, err := q.Reserve(ctx, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(jobs))
+
+	row := fly.New("fly_jobs", fly.WithConn("queue")).SelectOne(fly.WhereEq("id", id))
+	assert.Equal(t, nil, row.Err)
+	assert.NotEqual(t, "0", row.GetString("failed_at"))
+}
+
+func Test_Run(t *testing.T) {
+	q := New("push", WithConn("queue"), WithPollInterval(10*time.Millisecond))
+	for i := 0; i < 10; i++ {
+		_, err := q.Enqueue(context.Background(), "job", 0)
+		assert.Equal(t, nil, err)
+	}
+
+	var done int32
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for atomic.LoadInt32(&done) < 10 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		cancel()
+	}()
+	q.Run(ctx, 3, func(ctx context.Context, job *Job) error {
+		atomic.AddInt32(&done, 1)
+		return nil
+	})
+	assert.Equal(t, int32(10), atomic.LoadInt32(&done))
+
+	count, err := fly.New("fly_jobs", fly.WithConn("queue")).Count(fly.WhereEq("queue", "push"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(0), count)
+
+	_ = os.Remove(dsn)
+}
@@ -0,0 +1,95 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/daodao97/fly"
+)
+
+// Handler process the job, the job is acked when it returns nil, otherwise nacked.
+// ctx is done when the visibility timeout of the job expired.
+type Handler = func(ctx context.Context, job *Job) error
+
+// Run process jobs by concurrency workers until ctx is done, it returns after the running jobs finished
+func (q *Queue) Run(ctx context.Context, concurrency int, handler Handler) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		// wait for a free worker
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		n := 1
+	acquire:
+		for n < concurrency {
+			select {
+			case sem <- struct{}{}:
+				n++
+			default:
+				break acquire
+			}
+		}
+
+		jobs, err := q.Reserve(ctx, n)
+		if err != nil && ctx.Err() == nil {
+			fly.Error("queue reserve err", "queue:", q.name, "error:", err)
+		}
+		for i := len(jobs); i < n; i++ {
+			<-sem
+		}
+
+		for _, job := range jobs {
+			wg.Add(1)
+			go func(job *Job) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				q.process(job, handler)
+			}(job)
+		}
+
+		if len(jobs) == 0 {
+			select {
+			case <-time.After(q.pollInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// process the running job is not interrupted by the ctx of Run, so it can be acked after Run stopped
+func (q *Queue) process(job *Job, handler Handler) {
+	ctx, cancel := context.WithTimeout(context.Background(), q.visibility)
+	defer cancel()
+
+	err := func() (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("queue: job panic: %v", p)
+			}
+		}()
+		return handler(ctx, job)
+	}()
+
+	// the handler may use up ctx
+	if err == nil {
+		err = q.Ack(context.Background(), job)
+	} else {
+		err = q.Nack(context.Background(), job, err)
+	}
+	if err != nil {
+		fly.Error("queue ack err", "queue:", q.name, "id:", job.Id, "error:", err)
+	}
+}
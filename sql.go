@@ -3,6 +3,7 @@ package fly
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -14,7 +15,7 @@ import (
 var ErrRowBindingType = errors.New("binding dest type must be *struct **struct")
 var ErrRowsBindingType = errors.New("binding dest type must be *[]struct, *[]*struct")
 
-const selectMod = "select %s from `%s`"
+const selectMod = "select %s from %s"
 const insertMod = "insert into %s (%s) values (%s)"
 const updateMod = "update %s set %s"
 const deleteMod = "delete from %s"
@@ -73,15 +74,32 @@ func (r *Rows) Binding(dest interface{}) error {
 type Option = func(opts *Options)
 
 type Options struct {
-	database string
-	table    string
-	field    []string
-	where    []where
-	orderBy  []string
-	groupBy  string
-	limit    int
-	offset   int
-	value    []interface{}
+	database  string
+	table     string
+	alias     string
+	join      []join
+	field     []string
+	fieldSub  []subField
+	from      *subField
+	where     []where
+	orderBy   []order
+	groupBy   []string
+	having    []where
+	limit     int
+	offset    int
+	value     []interface{}
+	values    [][]interface{}
+	dialect   Dialect
+	returning string
+	upsert    *upsert
+	lock      string
+	lockWait  string
+	with      []cte
+	union     []union
+	columns   []string
+	trashed   string
+	unscoped  []string
+	scoped    bool
 }
 
 func table(table string) Option {
@@ -90,12 +108,243 @@ func table(table string) Option {
 	}
 }
 
+// Table set the table of a builder, mostly used by the options of a sub query
+func Table(name string) Option {
+	return table(name)
+}
+
+// returning the field returned by insert sql when the dialect not support LastInsertId
+func returning(field string) Option {
+	return func(opts *Options) {
+		opts.returning = field
+	}
+}
+
+type upsert struct {
+	conflict []string
+	update   []string
+}
+
+// conflictUpdate insert sql update the update columns when conflict on the conflict columns,
+// empty update keep the conflict row as it is
+func conflictUpdate(conflict []string, update []string) Option {
+	return func(opts *Options) {
+		opts.upsert = &upsert{conflict: conflict, update: update}
+	}
+}
+
+const (
+	lockUpdate = "update"
+	lockShare  = "share"
+)
+
+// lock the selected rows until the transaction end, only works in transaction
+func lock(mode string) Option {
+	return func(opts *Options) {
+		opts.lock = mode
+	}
+}
+
+// LockForUpdate select ... for update, the locking read is done by the write client
+func LockForUpdate() Option {
+	return lock(lockUpdate)
+}
+
+// LockForShare select ... for share (mysql 8.0+)
+func LockForShare() Option {
+	return lock(lockShare)
+}
+
+func lockWait(wait string) Option {
+	return func(opts *Options) {
+		if opts.lock == "" {
+			opts.lock = lockUpdate
+		}
+		opts.lockWait = wait
+	}
+}
+
+// SkipLocked skip the rows locked by others instead of waiting, for update if no lock mode is set
+func SkipLocked() Option {
+	return lockWait("skip locked")
+}
+
+// NoWait return error immediately if the rows are locked by others, for update if no lock mode is set
+func NoWait() Option {
+	return lockWait("nowait")
+}
+
+const (
+	trashedWith = "with"
+	trashedOnly = "only"
+)
+
+// WithTrashed the soft deleted rows are selected too
+func WithTrashed() Option {
+	return func(opts *Options) {
+		opts.trashed = trashedWith
+	}
+}
+
+// OnlyTrashed only the soft deleted rows are selected
+func OnlyTrashed() Option {
+	return func(opts *Options) {
+		opts.trashed = trashedOnly
+	}
+}
+
+// WithoutScope disable the global scopes of model (WithGlobalScope) by name, all of them if no name
+func WithoutScope(name ...string) Option {
+	if len(name) == 0 {
+		name = []string{"*"}
+	}
+	return func(opts *Options) {
+		opts.unscoped = append(opts.unscoped, name...)
+	}
+}
+
 func database(database string) Option {
 	return func(opts *Options) {
 		opts.database = database
 	}
 }
 
+// subQuery a set of options rendered as a nested select
+type subQuery []Option
+
+func (q subQuery) build(d Dialect) (string, []interface{}) {
+	opts := &Options{dialect: d}
+	for _, v := range q {
+		v(opts)
+	}
+	return selectBuilder(opts)
+}
+
+type cte struct {
+	name      string
+	recursive bool
+	query     subQuery
+}
+
+// WithCTE common table expression, with name as (select ...) select ..., the name can be referred as table by the query
+func WithCTE(name string, opts ...Option) Option {
+	return func(o *Options) {
+		o.with = append(o.with, cte{name: name, query: opts})
+	}
+}
+
+// WithRecursiveCTE recursive common table expression, the recursive part is added by UnionAll, e.g.
+//
+//	WithRecursiveCTE("tree",
+//		Table("category"), WhereEq("id", 1),
+//		UnionAll(Table("category"), Field("category.*"), Join("tree", On("category.pid", "=", "tree.id"))),
+//	)
+func WithRecursiveCTE(name string, opts ...Option) Option {
+	return func(o *Options) {
+		o.with = append(o.with, cte{name: name, recursive: true, query: opts})
+	}
+}
+
+type union struct {
+	all   bool
+	query subQuery
+}
+
+// Union combine the result of the query built by opts, the order by and limit of the main query apply to the whole result
+func Union(opts ...Option) Option {
+	return func(o *Options) {
+		o.union = append(o.union, union{query: opts})
+	}
+}
+
+func UnionAll(opts ...Option) Option {
+	return func(o *Options) {
+		o.union = append(o.union, union{all: true, query: opts})
+	}
+}
+
+type subField struct {
+	alias string
+	query subQuery
+}
+
+// FieldSub select a sub query as field, e.g. FieldSub("cnt", Table("followed"), AggregateCount("*"), ...)
+func FieldSub(alias string, opts ...Option) Option {
+	return func(o *Options) {
+		o.fieldSub = append(o.fieldSub, subField{alias: alias, query: opts})
+	}
+}
+
+// FromSub select from a derived table, e.g. select * from (select ...) as `alias`
+func FromSub(alias string, opts ...Option) Option {
+	return func(o *Options) {
+		o.from = &subField{alias: alias, query: opts}
+	}
+}
+
+type join struct {
+	kind  string
+	table string
+	on    []where
+}
+
+// Alias set the alias of the main table, e.g. Alias("u") => from `user` as `u`
+func Alias(name string) Option {
+	return func(opts *Options) {
+		opts.alias = name
+	}
+}
+
+func joinWith(kind, table string, on ...Option) Option {
+	opt := &Options{}
+	for _, v := range on {
+		v(opt)
+	}
+	return func(opts *Options) {
+		opts.join = append(opts.join, join{
+			kind:  kind,
+			table: quoteAs(table),
+			on:    opt.where,
+		})
+	}
+}
+
+// Join inner join table, table can be with alias like "level as l",
+// the on conditions are built by On and the Where* options
+func Join(table string, on ...Option) Option {
+	return joinWith("inner join", table, on...)
+}
+
+func LeftJoin(table string, on ...Option) Option {
+	return joinWith("left join", table, on...)
+}
+
+func RightJoin(table string, on ...Option) Option {
+	return joinWith("right join", table, on...)
+}
+
+// On compare two columns, e.g. On("u.level_id", "=", "l.id")
+func On(left, operator, right string) Option {
+	return func(opts *Options) {
+		opts.where = append(opts.where, where{
+			field:    left,
+			operator: operator,
+			value:    column(right),
+		})
+	}
+}
+
+func OrOn(left, operator, right string) Option {
+	return func(opts *Options) {
+		opts.where = append(opts.where, where{
+			field:    left,
+			operator: operator,
+			value:    column(right),
+			logic:    "or",
+		})
+	}
+}
+
 func Offset(offset int) Option {
 	return func(opts *Options) {
 		opts.offset = offset
@@ -118,19 +367,11 @@ func Pagination(pageNumber, pageSize int) []Option {
 func Field(name ...string) Option {
 	var _name []string
 	for _, v := range name {
-		if strings.Contains(v, " as ") {
-			tmp := strings.Split(v, " as ")
-			_name = append(_name, "`"+strings.Trim(tmp[0], " ")+"` as `"+strings.Trim(tmp[1], " ")+"`")
-		} else if strings.Contains(v, " AS ") {
-			tmp := strings.Split(v, " AS ")
-			_name = append(_name, "`"+strings.Trim(tmp[0], " ")+"` as `"+strings.Trim(tmp[1], " ")+"`")
-			_name = append(_name, "`"+v+"`")
-		} else {
-			_name = append(_name, "`"+v+"`")
-		}
+		_name = append(_name, quoteAs(v))
 	}
 	return func(opts *Options) {
 		opts.field = _name
+		opts.columns = append(opts.columns, name...)
 	}
 }
 
@@ -140,16 +381,51 @@ func FieldRaw(name string) Option {
 	}
 }
 
+// aggregate replace the select fields with a single aggregate expression
+func aggregate(expr string) Option {
+	return func(opts *Options) {
+		opts.field = []string{expr}
+		opts.fieldSub = nil
+	}
+}
+
+// aggregateField fn(`name`), the name is quoted as identifier except *
+func aggregateField(fn, name string) string {
+	if name == "*" {
+		return fn + "(*)"
+	}
+	return fn + "(" + quote(name) + ")"
+}
+
+func aggregateOf(fn, name string) Option {
+	expr := aggregate(aggregateField(fn, name) + " as aggregate")
+	return func(opts *Options) {
+		expr(opts)
+		opts.columns = append(opts.columns, name)
+	}
+}
+
 func AggregateSum(name string) Option {
-	return Field("sum(" + name + ") as aggregate")
+	return aggregateOf("sum", name)
 }
 
 func AggregateCount(name string) Option {
-	return FieldRaw("count(" + name + ") as count")
+	return func(opts *Options) {
+		opts.field = append(opts.field, aggregateField("count", name)+" as count")
+		opts.columns = append(opts.columns, name)
+	}
 }
 
 func AggregateMax(name string) Option {
-	return Field("max(" + name + ") as aggregate")
+	return aggregateOf("max", name)
+}
+
+func AggregateMin(name string) Option {
+	return aggregateOf("min", name)
+}
+
+func AggregateAvg(name string) Option {
+	return aggregateOf("avg", name)
 }
 
 func Value(val ...interface{}) Option {
@@ -158,12 +434,43 @@ func Value(val ...interface{}) Option {
 	}
 }
 
+// Values the value rows of multi rows insert, each row is in the order of Field
+func Values(rows ...[]interface{}) Option {
+	return func(opts *Options) {
+		opts.values = rows
+	}
+}
+
+// column marks a where value as a column reference, it is rendered quoted instead of bound
+type column string
+
+// Expression a raw sql expression used as the value of Update, Insert and Where,
+// it is rendered as it is with its own bindings instead of bound, e.g. fly.Expr("`views` + ?", 1)
+type Expression struct {
+	sql  string
+	args []interface{}
+}
+
+func Expr(sql string, args ...interface{}) Expression {
+	return Expression{sql: sql, args: args}
+}
+
+// bindValue the placeholder and args of a value
+func bindValue(value interface{}) (string, []interface{}) {
+	if e, ok := value.(Expression); ok {
+		return e.sql, e.args
+	}
+	return "?", []interface{}{value}
+}
+
 type where struct {
 	field    string
 	operator string
 	value    interface{}
 	logic    string
 	sub      []where
+	raw      string
+	args     []interface{}
 }
 
 func Where(field, operator string, value interface{}) Option {
@@ -356,6 +663,86 @@ func WhereOrNotIn(field string, value []interface{}) Option {
 	}
 }
 
+func WhereNull(field string) Option {
+	return func(opts *Options) {
+		opts.where = append(opts.where, where{
+			field:    field,
+			operator: "is null",
+		})
+	}
+}
+
+func WhereNotNull(field string) Option {
+	return func(opts *Options) {
+		opts.where = append(opts.where, where{
+			field:    field,
+			operator: "is not null",
+		})
+	}
+}
+
+func WhereOrNull(field string) Option {
+	return func(opts *Options) {
+		opts.where = append(opts.where, where{
+			field:    field,
+			operator: "is null",
+			logic:    "or",
+		})
+	}
+}
+
+func WhereOrNotNull(field string) Option {
+	return func(opts *Options) {
+		opts.where = append(opts.where, where{
+			field:    field,
+			operator: "is not null",
+			logic:    "or",
+		})
+	}
+}
+
+// WhereRaw raw condition with its bindings, e.g. WhereRaw("date(`created_at`) = ?", "2023-01-01")
+func WhereRaw(sql string, args ...interface{}) Option {
+	return func(opts *Options) {
+		opts.where = append(opts.where, where{
+			raw:  sql,
+			args: args,
+		})
+	}
+}
+
+func WhereOrRaw(sql string, args ...interface{}) Option {
+	return func(opts *Options) {
+		opts.where = append(opts.where, where{
+			raw:   sql,
+			args:  args,
+			logic: "or",
+		})
+	}
+}
+
+// WhereColumn compare two columns, e.g. WhereColumn("updated_at", ">", "created_at")
+func WhereColumn(first, operator, second string) Option {
+	return func(opts *Options) {
+		opts.where = append(opts.where, where{
+			field:    first,
+			operator: operator,
+			value:    column(second),
+		})
+	}
+}
+
+func WhereOrColumn(first, operator, second string) Option {
+	return func(opts *Options) {
+		opts.where = append(opts.where, where{
+			field:    first,
+			operator: operator,
+			value:    column(second),
+			logic:    "or",
+		})
+	}
+}
+
 func WhereGroup(opts ...Option) Option {
 	opt := &Options{}
 	for _, v := range opts {
@@ -382,6 +769,53 @@ func WhereOrGroup(opts ...Option) Option {
 	}
 }
 
+// groupOr group the conditions of opt if there is any or, so the conditions added before or after opt
+// are applied to all of them, e.g. a = ? or b = ? => (a = ? or b = ?) and deleted_at is null
+func groupOr(opt []Option) []Option {
+	start := 0
+	_opt := make([]Option, 0, len(opt)+2)
+	_opt = append(_opt, func(opts *Options) {
+		start = len(opts.where)
+	})
+	_opt = append(_opt, opt...)
+	return append(_opt, func(opts *Options) {
+		conds := opts.where[start:]
+		for _, v := range conds {
+			if v.logic == "or" {
+				opts.where = append(opts.where[:start:start], where{logic: "and", sub: append([]where{}, conds...)})
+				return
+			}
+		}
+	})
+}
+
+// WhereSub compare field with a sub query, e.g. WhereSub("score", ">", Table("user"), AggregateAvg("score"))
+func WhereSub(field, operator string, opts ...Option) Option {
+	return func(o *Options) {
+		o.where = append(o.where, where{
+			field:    field,
+			operator: operator,
+			value:    subQuery(opts),
+		})
+	}
+}
+
+func WhereInSub(field string, opts ...Option) Option {
+	return WhereSub(field, "in", opts...)
+}
+
+func WhereNotInSub(field string, opts ...Option) Option {
+	return WhereSub(field, "not in", opts...)
+}
+
+func WhereExists(opts ...Option) Option {
+	return WhereSub("", "exists", opts...)
+}
+
+func WhereNotExists(opts ...Option) Option {
+	return WhereSub("", "not exists", opts...)
+}
+
 func WhereLike(field string, value interface{}) Option {
 	return func(opts *Options) {
 		opts.where = append(opts.where, where{
@@ -444,25 +878,124 @@ func WhereOrFindInSet(field string, value interface{}) Option {
 	}
 }
 
+type order struct {
+	field string
+	desc  bool
+}
+
+func (o order) String() string {
+	if o.desc {
+		return quote(o.field) + " desc"
+	}
+	return quote(o.field) + " asc"
+}
+
 func OrderByDesc(field string) Option {
 	return func(opts *Options) {
-		opts.orderBy = append(opts.orderBy, "`"+field+"` "+"desc")
+		opts.orderBy = append(opts.orderBy, order{field: field, desc: true})
 	}
 }
 
 func OrderByAsc(field string) Option {
 	return func(opts *Options) {
-		opts.orderBy = append(opts.orderBy, "`"+field+"` "+"asc")
+		opts.orderBy = append(opts.orderBy, order{field: field})
+	}
+}
+
+// GroupBy group by fields, GroupBy("a", "b") or GroupBy("a, b")
+func GroupBy(field ...string) Option {
+	var _name, _field []string
+	for _, v := range field {
+		for _, f := range strings.Split(v, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				_name = append(_name, f)
+				_field = append(_field, quote(f))
+			}
+		}
+	}
+	return func(opts *Options) {
+		opts.groupBy = append(opts.groupBy, _field...)
+		opts.columns = append(opts.columns, _name...)
+	}
+}
+
+// Having build having conditions by the Where* options, e.g. Having(WhereGt("total", 10))
+func Having(opts ...Option) Option {
+	opt := &Options{}
+	for _, v := range opts {
+		v(opt)
+	}
+	return func(opts *Options) {
+		opts.having = append(opts.having, opt.where...)
 	}
 }
 
-func GroupBy(field string) Option {
+// HavingRaw e.g. HavingRaw("sum(`score`) > ?", 100)
+func HavingRaw(sql string, args ...interface{}) Option {
 	return func(opts *Options) {
-		opts.groupBy = field
+		opts.having = append(opts.having, where{
+			raw:  sql,
+			args: args,
+		})
+	}
+}
+
+var asSplit = regexp.MustCompile(`(?i)\s+(?:as\s+)?`)
+
+// quote quote identifier with backtick, support table.column and *,
+// the backtick in name is escaped (and the double quote for postgres by Dialect.Rebind),
+// so it's safe to quote the name from user input.
+// a part already quoted (`name`) is kept.
+func quote(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "*" {
+		return name
+	}
+	parts := splitIdent(name)
+	for i, v := range parts {
+		if v == "*" && i > 0 && i == len(parts)-1 {
+			continue
+		}
+		parts[i] = "`" + strings.ReplaceAll(unquote(v), "`", "``") + "`"
+	}
+	return strings.Join(parts, ".")
+}
+
+// unquote strip the backticks of part and unescape the doubled ones
+func unquote(part string) string {
+	if len(part) >= 2 && part[0] == '`' && part[len(part)-1] == '`' {
+		return strings.ReplaceAll(part[1:len(part)-1], "``", "`")
+	}
+	return part
+}
+
+// splitIdent split name by the dots out of backticks, e.g. `a.b`.c => [`a.b`, c]
+func splitIdent(name string) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i, c := range name {
+		switch {
+		case c == '`':
+			quoted = !quoted
+		case c == '.' && !quoted:
+			parts = append(parts, name[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, name[start:])
+}
+
+// quoteAs quote identifier with alias, e.g. "u.name as user_name" => `u`.`name` as `user_name`
+func quoteAs(name string) string {
+	tmp := asSplit.Split(strings.TrimSpace(name), 2)
+	if len(tmp) == 2 {
+		return quote(tmp[0]) + " as " + quote(tmp[1])
 	}
+	return quote(name)
 }
 
-func whereBuilder(condition []where) (sql string, args []interface{}) {
+func whereBuilder(condition []where, d Dialect) (sql string, args []interface{}) {
 	if len(condition) == 0 {
 		return "", nil
 	}
@@ -476,7 +1009,21 @@ func whereBuilder(condition []where) (sql string, args []interface{}) {
 			}
 		}
 
-		if v.field != "" {
+		if v.raw != "" {
+			// parenthesized, so the or of raw sql can't escape from the conditions around it
+			tokens = append(tokens, "("+v.raw+")")
+			args = append(args, v.args...)
+		}
+
+		if q, ok := v.value.(subQuery); ok {
+			_sql, _args := q.build(d)
+			if v.field != "" {
+				tokens = append(tokens, fmt.Sprintf("%s %s (%s)", quote(v.field), v.operator, _sql))
+			} else {
+				tokens = append(tokens, fmt.Sprintf("%s (%s)", v.operator, _sql))
+			}
+			args = append(args, _args...)
+		} else if v.field != "" {
 			switch v.operator {
 			case "in", "not in":
 				val := v.value.([]interface{})
@@ -484,23 +1031,37 @@ func whereBuilder(condition []where) (sql string, args []interface{}) {
 				for range val {
 					placeholder = append(placeholder, "?")
 				}
-				tokens = append(tokens, fmt.Sprintf("`%s` %s (%s)", v.field, v.operator, strings.Join(placeholder, ",")))
+				tokens = append(tokens, fmt.Sprintf("%s %s (%s)", quote(v.field), v.operator, strings.Join(placeholder, ",")))
 				args = append(args, val...)
 			case "between":
 				val := v.value.([]interface{})
-				tokens = append(tokens, fmt.Sprintf("`%s` %s ? and ?", v.field, v.operator))
+				tokens = append(tokens, fmt.Sprintf("%s %s ? and ?", quote(v.field), v.operator))
 				args = append(args, val...)
+			case "is null", "is not null":
+				tokens = append(tokens, fmt.Sprintf("%s %s", quote(v.field), v.operator))
 			case "find_in_set":
-				tokens = append(tokens, fmt.Sprintf("find_in_set(?, %s)", v.field))
+				tokens = append(tokens, fmt.Sprintf("find_in_set(?, %s)", quote(v.field)))
 				args = append(args, v.value)
 			default:
-				tokens = append(tokens, fmt.Sprintf("`%s` %s ?", v.field, v.operator))
-				args = append(args, v.value)
+				if v.value == nil && (v.operator == "=" || v.operator == "!=" || v.operator == "<>") {
+					// = null never matches
+					if v.operator == "=" {
+						tokens = append(tokens, fmt.Sprintf("%s is null", quote(v.field)))
+					} else {
+						tokens = append(tokens, fmt.Sprintf("%s is not null", quote(v.field)))
+					}
+				} else if col, ok := v.value.(column); ok {
+					tokens = append(tokens, fmt.Sprintf("%s %s %s", quote(v.field), v.operator, quote(string(col))))
+				} else {
+					_sql, _args := bindValue(v.value)
+					tokens = append(tokens, fmt.Sprintf("%s %s %s", quote(v.field), v.operator, _sql))
+					args = append(args, _args...)
+				}
 			}
 		}
 
 		if v.sub != nil {
-			_sql, _args := whereBuilder(v.sub)
+			_sql, _args := whereBuilder(v.sub, d)
 			tokens = append(tokens, "("+_sql+")")
 			args = append(args, _args...)
 		}
@@ -513,31 +1074,112 @@ func SelectBuilder(opts ...Option) (sql string, args []interface{}) {
 	for _, v := range opts {
 		v(_opts)
 	}
+	sql, args = selectBuilder(_opts)
+	return getDialect(_opts).Rebind(sql), args
+}
+
+func selectBuilder(_opts *Options) (sql string, args []interface{}) {
+	d := getDialect(_opts)
+
+	var _with []string
+	recursive := false
+	for _, v := range _opts.with {
+		_sql, _args := v.query.build(d)
+		_with = append(_with, quote(v.name)+" as ("+_sql+")")
+		args = append(args, _args...)
+		recursive = recursive || v.recursive
+	}
+
+	_fields := _opts.field
+	for _, v := range _opts.fieldSub {
+		_sql, _args := v.query.build(d)
+		_fields = append(_fields, "("+_sql+") as "+quote(v.alias))
+		args = append(args, _args...)
+	}
 
-	_where, args := whereBuilder(_opts.where)
 	_field := "*"
 
-	if len(_opts.field) > 0 {
-		_field = strings.Join(_opts.field, ", ")
+	if len(_fields) > 0 {
+		_field = strings.Join(_fields, ", ")
 	}
 
-	sql = fmt.Sprintf(selectMod, _field, _opts.table)
+	_table := quote(_opts.table)
+	if _opts.from != nil {
+		_sql, _args := _opts.from.query.build(d)
+		_table = "(" + _sql + ") as " + quote(_opts.from.alias)
+		args = append(args, _args...)
+	} else if _opts.alias != "" {
+		_table = _table + " as " + quote(_opts.alias)
+	}
+
+	sql = fmt.Sprintf(selectMod, _field, _table)
+	if len(_with) > 0 {
+		if recursive {
+			sql = "with recursive " + strings.Join(_with, ", ") + " " + sql
+		} else {
+			sql = "with " + strings.Join(_with, ", ") + " " + sql
+		}
+	}
 
+	for _, j := range _opts.join {
+		sql = sql + " " + j.kind + " " + j.table
+		if len(j.on) > 0 {
+			_on, _args := whereBuilder(j.on, d)
+			sql = sql + " on " + _on
+			args = append(args, _args...)
+		}
+	}
+
+	_where, _args := whereBuilder(_opts.where, d)
+	args = append(args, _args...)
 	if _where != "" {
 		sql = sql + " where " + _where
 	}
 
-	if len(_opts.orderBy) > 0 {
-		sql = sql + " order by " + strings.Join(_opts.orderBy, ", ")
+	if len(_opts.groupBy) > 0 {
+		sql = sql + " group by " + strings.Join(_opts.groupBy, ", ")
+	}
+
+	if len(_opts.having) > 0 {
+		_having, _args := whereBuilder(_opts.having, d)
+		sql = sql + " having " + _having
+		args = append(args, _args...)
+	}
+
+	for _, v := range _opts.union {
+		_opts := &Options{dialect: d}
+		for _, o := range v.query {
+			o(_opts)
+		}
+		_sql, _args := selectBuilder(_opts)
+		// sqlite not allow parentheses, mysql need them for the order by or limit of one query
+		if len(_opts.orderBy) > 0 || _opts.limit != 0 {
+			_sql = "(" + _sql + ")"
+		}
+		if v.all {
+			sql = sql + " union all " + _sql
+		} else {
+			sql = sql + " union " + _sql
+		}
+		args = append(args, _args...)
 	}
 
-	if _opts.groupBy != "" {
-		sql = sql + " group by " + _opts.groupBy
+	if len(_opts.orderBy) > 0 {
+		var _order []string
+		for _, v := range _opts.orderBy {
+			_order = append(_order, v.String())
+		}
+		sql = sql + " order by " + strings.Join(_order, ", ")
 	}
 
 	if _opts.limit != 0 {
-		sql = sql + " limit ? offset ? "
-		args = append(args, _opts.limit, _opts.offset)
+		_limit, _args := d.Limit(_opts.limit, _opts.offset)
+		sql = sql + _limit
+		args = append(args, _args...)
+	}
+
+	if _opts.lock != "" {
+		sql = sql + d.Lock(_opts.lock, _opts.lockWait)
 	}
 
 	return sql, args
@@ -545,9 +1187,9 @@ func SelectBuilder(opts ...Option) (sql string, args []interface{}) {
 
 func getTable(opt *Options) string {
 	if opt.database == "" {
-		return opt.table
+		return quote(opt.table)
 	}
-	return opt.database + "." + opt.table
+	return quote(opt.database + "." + opt.table)
 }
 
 func InsertBuilder(opts ...Option) (sql string, args []interface{}) {
@@ -555,13 +1197,28 @@ func InsertBuilder(opts ...Option) (sql string, args []interface{}) {
 	for _, v := range opts {
 		v(_opts)
 	}
-	var _val []string
-	for range _opts.field {
-		_val = append(_val, "?")
+	_rows := [][]interface{}{_opts.value}
+	if len(_opts.values) > 0 {
+		_rows = _opts.values
 	}
-	sql = fmt.Sprintf(insertMod, getTable(_opts), strings.Join(_opts.field, ", "), strings.Join(_val, ","))
-	args = _opts.value
-	return sql, args
+	var _tuple []string
+	for _, row := range _rows {
+		var _val []string
+		for _, v := range row {
+			_sql, _args := bindValue(v)
+			_val = append(_val, _sql)
+			args = append(args, _args...)
+		}
+		_tuple = append(_tuple, strings.Join(_val, ","))
+	}
+	sql = fmt.Sprintf(insertMod, getTable(_opts), strings.Join(_opts.field, ", "), strings.Join(_tuple, "),("))
+	if _opts.upsert != nil {
+		sql = sql + getDialect(_opts).Upsert(_opts.upsert.conflict, _opts.upsert.update)
+	}
+	if _opts.returning != "" {
+		sql = sql + getDialect(_opts).Returning(_opts.returning)
+	}
+	return getDialect(_opts).Rebind(sql), args
 }
 
 func UpdateBuilder(opts ...Option) (sql string, args []interface{}) {
@@ -570,17 +1227,22 @@ func UpdateBuilder(opts ...Option) (sql string, args []interface{}) {
 		v(_opts)
 	}
 	var _val []string
-	for _, v := range _opts.field {
-		_val = append(_val, v+" = ?")
+	for i, v := range _opts.field {
+		var value interface{}
+		if i < len(_opts.value) {
+			value = _opts.value[i]
+		}
+		_sql, _args := bindValue(value)
+		_val = append(_val, v+" = "+_sql)
+		args = append(args, _args...)
 	}
 	sql = fmt.Sprintf(updateMod, getTable(_opts), strings.Join(_val, ","))
-	args = _opts.value
 	if len(_opts.where) > 0 {
-		_where, _args := whereBuilder(_opts.where)
+		_where, _args := whereBuilder(_opts.where, getDialect(_opts))
 		sql = sql + " where " + _where
 		args = append(args, _args...)
 	}
-	return sql, args
+	return getDialect(_opts).Rebind(sql), args
 }
 
 func DeleteBuilder(opts ...Option) (sql string, args []interface{}) {
@@ -588,11 +1250,11 @@ func DeleteBuilder(opts ...Option) (sql string, args []interface{}) {
 	for _, v := range opts {
 		v(_opts)
 	}
-	sql = fmt.Sprintf(deleteMod, _opts.table)
+	sql = fmt.Sprintf(deleteMod, getTable(_opts))
 	if len(_opts.where) > 0 {
-		_where, _args := whereBuilder(_opts.where)
+		_where, _args := whereBuilder(_opts.where, getDialect(_opts))
 		sql = sql + " where " + _where
 		args = append(args, _args...)
 	}
-	return sql, args
+	return getDialect(_opts).Rebind(sql), args
 }
@@ -3,6 +3,8 @@ package fly
 import (
 	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestSelectBuilder(t *testing.T) {
@@ -27,6 +29,110 @@ func TestSelectBuilder(t *testing.T) {
 	fmt.Println(sql, args)
 }
 
+func TestSelectBuilderNull(t *testing.T) {
+	sql, args := SelectBuilder(
+		table("user"),
+		WhereEq("deleted_at", nil),
+		WhereNotEq("email", nil),
+		WhereNotNull("phone"),
+		WhereOrNull("nickname"),
+		WhereRaw("date(`created_at`) = ?", "2023-01-01"),
+		WhereColumn("updated_at", ">", "created_at"),
+		WhereGroup(WhereOrRaw("1 = 1"), WhereOrColumn("a", "=", "b")),
+	)
+	assert.Equal(t, "select * from `user` where `deleted_at` is null and `email` is not null and `phone` is not null or `nickname` is null "+
+		"and (date(`created_at`) = ?) and `updated_at` > `created_at` and ((1 = 1) or `a` = `b`)", sql)
+	assert.Equal(t, []interface{}{"2023-01-01"}, args)
+}
+
+func TestSelectBuilderJoin(t *testing.T) {
+	sql, args := SelectBuilder(
+		table("user"),
+		Alias("u"),
+		Field("u.id", "u.name", "l.name as level_name", "f.*"),
+		LeftJoin("level as l", On("u.level_id", "=", "l.id"), WhereEq("l.status", 1)),
+		Join("followed f", On("f.uid", "=", "u.id")),
+		WhereGt("u.id", 10),
+		OrderByDesc("u.id"),
+	)
+	assert.Equal(t, "select `u`.`id`, `u`.`name`, `l`.`name` as `level_name`, `f`.* from `user` as `u` "+
+		"left join `level` as `l` on `u`.`level_id` = `l`.`id` and `l`.`status` = ? "+
+		"inner join `followed` as `f` on `f`.`uid` = `u`.`id` "+
+		"where `u`.`id` > ? order by `u`.`id` desc", sql)
+	assert.Equal(t, []interface{}{1, 10}, args)
+}
+
+func TestSelectBuilderGroupBy(t *testing.T) {
+	sql, args := SelectBuilder(
+		table("order"),
+		Field("uid", "status"),
+		FieldRaw("sum(`amount`) as total"),
+		WhereGt("id", 1),
+		OrderByDesc("total"),
+		GroupBy("uid", "status"),
+		Having(WhereGt("total", 100), WhereOrLt("total", 10)),
+		HavingRaw("count(*) > ?", 2),
+		Limit(10),
+	)
+	assert.Equal(t, "select `uid`, `status`, sum(`amount`) as total from `order` where `id` > ? "+
+		"group by `uid`, `status` having `total` > ? or `total` < ? and (count(*) > ?) "+
+		"order by `total` desc limit ? offset ? ", sql)
+	assert.Equal(t, []interface{}{1, 100, 10, 2, 10, 0}, args)
+}
+
+func TestSelectBuilderSub(t *testing.T) {
+	sql, args := SelectBuilder(
+		FieldSub("follower", Table("followed"), AggregateCount("*"), On("followed.uid", "=", "u.id"), WhereEq("status", 1)),
+		FromSub("u", Table("user"), WhereEq("is_deleted", 0)),
+		WhereInSub("level_id", Table("level"), Field("id"), WhereGt("score", 10)),
+		WhereExists(Table("role"), On("role.uid", "=", "u.id")),
+		WhereNotExists(Table("ban"), On("ban.uid", "=", "u.id"), WhereEq("type", 2)),
+		WhereSub("age", ">", Table("user"), AggregateAvg("age")),
+		Limit(10),
+	)
+	assert.Equal(t, "select (select count(*) as count from `followed` where `followed`.`uid` = `u`.`id` and `status` = ?) as `follower` "+
+		"from (select * from `user` where `is_deleted` = ?) as `u` "+
+		"where `level_id` in (select `id` from `level` where `score` > ?) "+
+		"and exists (select * from `role` where `role`.`uid` = `u`.`id`) "+
+		"and not exists (select * from `ban` where `ban`.`uid` = `u`.`id` and `type` = ?) "+
+		"and `age` > (select avg(`age`) as aggregate from `user`) limit ? offset ? ", sql)
+	assert.Equal(t, []interface{}{1, 0, 10, 2, 10, 0}, args)
+}
+
+func TestSelectBuilderUnion(t *testing.T) {
+	sql, args := SelectBuilder(
+		table("user"),
+		Field("id", "name"),
+		WhereEq("status", 1),
+		UnionAll(Table("admin"), Field("id", "name"), WhereEq("status", 2)),
+		Union(Table("guest"), Field("id", "name"), OrderByDesc("id"), Limit(5)),
+		OrderByAsc("id"),
+		Limit(10),
+	)
+	assert.Equal(t, "select `id`, `name` from `user` where `status` = ? "+
+		"union all select `id`, `name` from `admin` where `status` = ? "+
+		"union (select `id`, `name` from `guest` order by `id` desc limit ? offset ? ) "+
+		"order by `id` asc limit ? offset ? ", sql)
+	assert.Equal(t, []interface{}{1, 2, 5, 0, 10, 0}, args)
+}
+
+func TestSelectBuilderCTE(t *testing.T) {
+	sql, args := SelectBuilder(
+		WithRecursiveCTE("tree",
+			Table("category"), Field("id", "pid"), WhereEq("id", 1),
+			UnionAll(Table("category"), Field("category.id", "category.pid"), Join("tree", On("category.pid", "=", "tree.id"))),
+		),
+		WithCTE("active", Table("category"), WhereEq("status", 1)),
+		table("tree"),
+		WhereInSub("id", Table("active"), Field("id")),
+	)
+	assert.Equal(t, "with recursive `tree` as (select `id`, `pid` from `category` where `id` = ? "+
+		"union all select `category`.`id`, `category`.`pid` from `category` inner join `tree` on `category`.`pid` = `tree`.`id`), "+
+		"`active` as (select * from `category` where `status` = ?) "+
+		"select * from `tree` where `id` in (select `id` from `active`)", sql)
+	assert.Equal(t, []interface{}{1, 1}, args)
+}
+
 func TestInsertBuilder(t *testing.T) {
 	sql, args := InsertBuilder(
 		table("user"),
@@ -36,6 +142,16 @@ func TestInsertBuilder(t *testing.T) {
 	fmt.Println(sql, args)
 }
 
+func TestInsertBuilderValues(t *testing.T) {
+	sql, args := InsertBuilder(
+		table("user"),
+		Field("name", "status"),
+		Values([]interface{}{"a", 1}, []interface{}{"b", 2}),
+	)
+	assert.Equal(t, "insert into `user` (`name`, `status`) values (?,?),(?,?)", sql)
+	assert.Equal(t, []interface{}{"a", 1, "b", 2}, args)
+}
+
 func TestUpdateBuilder(t *testing.T) {
 	sql, args := UpdateBuilder(
 		table("user"),
@@ -46,6 +162,21 @@ func TestUpdateBuilder(t *testing.T) {
 	fmt.Println(sql, args)
 }
 
+func TestUpdateBuilderExpr(t *testing.T) {
+	sql, args := UpdateBuilder(
+		table("post"),
+		Field("views", "title"),
+		Value(Expr("`views` + ?", 1), "fly"),
+		WhereEq("id", 1),
+	)
+	assert.Equal(t, "update `post` set `views` = `views` + ?,`title` = ? where `id` = ?", sql)
+	assert.Equal(t, []interface{}{1, "fly", 1}, args)
+
+	sql, args = InsertBuilder(table("post"), Field("title", "created_at"), Value("fly", Expr("now()")))
+	assert.Equal(t, "insert into `post` (`title`, `created_at`) values (?,now())", sql)
+	assert.Equal(t, []interface{}{"fly"}, args)
+}
+
 func TestDeleteBuilder(t *testing.T) {
 	sql, args := DeleteBuilder(
 		table("user"),
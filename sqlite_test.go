@@ -0,0 +1,26 @@
+package fly
+
+import (
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+// sqliteConn the connection name of the in-memory sqlite database of test
+func sqliteConn(t *testing.T) string {
+	return strings.ReplaceAll(t.Name(), "/", "_")
+}
+
+// sqliteModel the model of table created by ddl in a new in-memory sqlite database
+func sqliteModel(t *testing.T, table, ddl string, with ...With) Model {
+	conn := sqliteConn(t)
+	err := Init(map[string]*Config{
+		conn: {DSN: "file:" + conn + "?mode=memory&cache=shared", Driver: "sqlite3", MaxOpenConn: 1},
+	})
+	require.Equal(t, nil, err)
+	_, err = New(table, WithConn(conn)).Exec(ddl)
+	require.Equal(t, nil, err)
+	return New(table, append([]With{WithConn(conn)}, with...)...)
+}
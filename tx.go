@@ -0,0 +1,170 @@
+package fly
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Tx a transaction of the connection, bind it to models by m.WithTx(tx) or m.Ctx(tx.Context()),
+// the nested transaction started by tx.Begin or tx.Transaction is a savepoint of its parent,
+// so is the one started by BeginContext or TransactionContext with the ctx of tx (tx.Context())
+type Tx struct {
+	tx          *sql.Tx
+	ctx         context.Context
+	conn        string
+	parent      *Tx
+	savepoint   string
+	seq         int
+	afterCommit []func()
+}
+
+// Begin start a transaction on the connection, it must be finished by Commit or Rollback
+func Begin(conn string) (*Tx, error) {
+	return BeginContext(context.Background(), conn)
+}
+
+// BeginContext the transaction is rolled back if ctx is done before committed
+func BeginContext(ctx context.Context, conn string) (*Tx, error) {
+	_db, err := db(conn)
+	if err != nil {
+		return nil, err
+	}
+	return BeginWith(ctx, _db, conn)
+}
+
+// BeginWith start a transaction on a custom db, conn is the name of the connection
+// which relation models (HasOpts.Conn) join the transaction
+func BeginWith(ctx context.Context, db *sql.DB, conn string) (*Tx, error) {
+	if parent, ok := TxFromContext(ctx); ok && parent.conn == conn {
+		return parent.Begin()
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "fly.Begin err")
+	}
+	t := &Tx{tx: tx, conn: conn}
+	t.ctx = context.WithValue(ctx, txKey{}, t)
+	return t, nil
+}
+
+type txKey struct{}
+
+// TxFromContext the transaction of ctx, which is the ctx of tx (tx.Context()) or derived from it
+func TxFromContext(ctx context.Context) (*Tx, bool) {
+	t, ok := ctx.Value(txKey{}).(*Tx)
+	return t, ok
+}
+
+// Context the ctx of transaction, pass it to the other services so their TransactionContext join the transaction
+func (t *Tx) Context() context.Context {
+	return t.ctx
+}
+
+// Transaction run fn in a transaction, it's committed when fn return nil,
+// and rolled back when fn return error or panic.
+// it always starts a new transaction, use TransactionContext with tx.Context() or tx.Transaction to nest it
+func Transaction(conn string, fn func(tx *Tx) error) (err error) {
+	return TransactionContext(context.Background(), conn, fn)
+}
+
+// TransactionContext the same as Transaction, it's a nested transaction if ctx carries a transaction of conn
+func TransactionContext(ctx context.Context, conn string, fn func(tx *Tx) error) (err error) {
+	tx, err := BeginContext(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return tx.run(fn)
+}
+
+func (t *Tx) run(fn func(tx *Tx) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			_ = t.Rollback()
+			panic(p)
+		}
+	}()
+	if err = fn(t); err != nil {
+		if rbErr := t.Rollback(); rbErr != nil {
+			Error("transaction rollback err", "error:", rbErr)
+		}
+		return err
+	}
+	return t.Commit()
+}
+
+// Transaction run fn in a nested transaction, its failure only roll back to the savepoint
+func (t *Tx) Transaction(fn func(tx *Tx) error) error {
+	nested, err := t.Begin()
+	if err != nil {
+		return err
+	}
+	return nested.run(fn)
+}
+
+// Begin start a nested transaction by savepoint
+func (t *Tx) Begin() (*Tx, error) {
+	root := t
+	for root.parent != nil {
+		root = root.parent
+	}
+	root.seq++
+	name := fmt.Sprintf("fly_sp_%d", root.seq)
+	if _, err := t.tx.ExecContext(t.ctx, "savepoint "+name); err != nil {
+		return nil, errors.Wrap(err, "fly.Begin savepoint err")
+	}
+	nested := &Tx{tx: t.tx, conn: t.conn, parent: t, savepoint: name}
+	nested.ctx = context.WithValue(t.ctx, txKey{}, nested)
+	return nested, nil
+}
+
+// AfterCommit register fn called after the outermost transaction committed,
+// it's discarded when the transaction (or the nested one registered it) rolled back
+func (t *Tx) AfterCommit(fn func()) {
+	t.afterCommit = append(t.afterCommit, fn)
+}
+
+func (t *Tx) Commit() error {
+	if t.parent != nil {
+		if _, err := t.tx.ExecContext(t.ctx, "release savepoint "+t.savepoint); err != nil {
+			return errors.Wrap(err, "fly.Commit release savepoint err")
+		}
+		t.parent.afterCommit = append(t.parent.afterCommit, t.afterCommit...)
+		t.afterCommit = nil
+		return nil
+	}
+	if err := t.tx.Commit(); err != nil {
+		return err
+	}
+	for _, fn := range t.afterCommit {
+		fn()
+	}
+	t.afterCommit = nil
+	return nil
+}
+
+func (t *Tx) Rollback() error {
+	t.afterCommit = nil
+	if t.parent != nil {
+		if _, err := t.tx.ExecContext(t.ctx, "rollback to savepoint "+t.savepoint); err != nil {
+			return errors.Wrap(err, "fly.Rollback savepoint err")
+		}
+		_, err := t.tx.ExecContext(t.ctx, "release savepoint "+t.savepoint)
+		return err
+	}
+	return t.tx.Rollback()
+}
+
+func (t *Tx) Conn() string {
+	return t.conn
+}
+
+func (t *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return t.tx.ExecContext(t.ctx, query, args...)
+}
+
+func (t *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return t.tx.QueryContext(t.ctx, query, args...)
+}
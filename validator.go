@@ -1,6 +1,7 @@
 package fly
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pkg/errors"
@@ -16,6 +17,7 @@ type ValidInfo struct {
 	Model Model
 	Label string
 	Msg   string
+	Ctx   context.Context
 }
 
 func mergeOpt(v1, v2 *ValidInfo) *ValidInfo {
@@ -34,6 +36,9 @@ func mergeOpt(v1, v2 *ValidInfo) *ValidInfo {
 	if v2.Msg != "" {
 		v1.Msg = v2.Msg
 	}
+	if v2.Ctx != nil {
+		v1.Ctx = v2.Ctx
+	}
 	return v1
 }
 
@@ -69,6 +74,12 @@ func withRow(row map[string]interface{}) ValidOpt {
 	}
 }
 
+func withContext(ctx context.Context) ValidOpt {
+	return func(v *ValidInfo) {
+		v.Ctx = ctx
+	}
+}
+
 func WithModel(m Model) ValidOpt {
 	return func(v *ValidInfo) {
 		v.Model = m
@@ -114,7 +125,7 @@ func Required(opt ...ValidOpt) Valid {
 func IfRequired(ifField string, opt ...ValidOpt) Valid {
 	v1 := NewValidOpt(opt...)
 	return ValidWrap(func(v *ValidInfo) error {
-		if err := Required()(NewValidOpt(withField(ifField), withRow(v.Row), WithModel(v.Model))); err != nil {
+		if err := Required()(NewValidOpt(withField(ifField), withRow(v.Row), WithModel(v.Model), withContext(v.Ctx))); err != nil {
 			return nil
 		}
 
@@ -122,6 +133,7 @@ func IfRequired(ifField string, opt ...ValidOpt) Valid {
 			withField(v.Field),
 			withRow(v.Row),
 			WithModel(v.Model),
+			withContext(v.Ctx),
 			WithMsg(msg(fmt.Sprintf("当 %s 存在时 %s 是必须的", ifField, v.Field), v.Msg)),
 		))
 	}, v1)